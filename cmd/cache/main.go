@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure"
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure/wiring"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"github.com/spf13/viper"
+)
+
+const usage = "usage: cache check|repair"
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != "check" && os.Args[1] != "repair") {
+		log.Fatal(usage)
+	}
+
+	if err := infrastructure.InitConfig(); err != nil {
+		log.Fatalf("Error config: %v", err)
+	}
+	if err := logger.InitLogger(); err != nil {
+		log.Fatalf("Error logger: %v", err)
+	}
+	infrastructure.InitDatabase()
+	infrastructure.InitCache()
+
+	todoCache, _ := wiring.TodoCache()
+	// The checker only needs the strategy, not the write-behind buffer that would come with it.
+	cacheWrite := service.CacheWrite{Strategy: viper.GetString("cache.write.strategy")}
+	checker := service.NewCacheChecker(wiring.TodoRepositories().Todo, todoCache, wiring.TodoCodec(), wiring.TodoCacheSchema(), wiring.TodoCacheTTL(), cacheWrite)
+
+	report, err := checker.Check(context.Background(), os.Args[1] == "repair")
+	if err != nil {
+		log.Fatalf("Error checking cache: %v", err)
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Error encoding report: %v", err)
+	}
+	fmt.Println(string(output))
+
+	if !report.Consistent() && !report.Repaired {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure"
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure/migration"
+)
+
+const usage = "usage: migrate up|down|status|to <version>"
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	if err := infrastructure.InitConfig(); err != nil {
+		log.Fatalf("Error config: %v", err)
+	}
+	infrastructure.InitDatabase()
+	if infrastructure.Db == nil {
+		log.Fatal("storage.repository has no database to migrate")
+	}
+
+	migrator, err := migration.NewMigrator(infrastructure.Db)
+	if err != nil {
+		log.Fatalf("Error loading migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "to":
+		if len(os.Args) < 3 {
+			log.Fatal(usage)
+		}
+		version, convErr := strconv.Atoi(os.Args[2])
+		if convErr != nil {
+			log.Fatalf("Invalid version %q: %v", os.Args[2], convErr)
+		}
+		err = migrator.To(ctx, version)
+	case "status":
+		err = printStatus(ctx, migrator)
+	default:
+		log.Fatal(usage)
+	}
+	if err != nil {
+		log.Fatalf("Error migrating: %v", err)
+	}
+}
+
+func printStatus(ctx context.Context, migrator *migration.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d  %-30s %s\n", status.Version, status.Name, appliedAt)
+	}
+	return nil
+}
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/VanillaSkys/todo_fiber/cmd/web/router"
 	"github.com/VanillaSkys/todo_fiber/internal/infrastructure"
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure/migration"
 	"github.com/VanillaSkys/todo_fiber/internal/logger"
 	"github.com/VanillaSkys/todo_fiber/internal/middleware"
 	"github.com/gofiber/fiber/v3"
@@ -17,7 +18,7 @@ import (
 func main() {
 	app := fiber.New()
 
-	if err := InitConfig(); err != nil {
+	if err := infrastructure.InitConfig(); err != nil {
 		log.Fatalf("Error config: %v", err)
 	}
 
@@ -27,11 +28,16 @@ func main() {
 	defer logger.SyncLogger()
 
 	InitTimeZone()
-	infrastructure.InitPostgres()
-	infrastructure.InitRedis()
+	infrastructure.InitDatabase()
+	infrastructure.InitCache()
+
+	if infrastructure.Db != nil {
+		InitSchema()
+	}
 
 	app.Use(cors.New())
 	app.Use(middleware.SetRequestId())
+	app.Use(middleware.SetActor())
 
 	router.SetupApiRoutes(app)
 
@@ -49,15 +55,21 @@ func InitTimeZone() {
 	time.Local = ict
 }
 
-func InitConfig() error {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AutomaticEnv()
-	viper.EnvKeyReplacer(strings.NewReplacer(".", "_"))
+// InitSchema refuses to start on an outdated schema, or migrates it when database.auto_migrate is set.
+func InitSchema() {
+	migrator, err := migration.NewMigrator(infrastructure.Db)
+	if err != nil {
+		log.Fatalf("Error loading migrations: %v", err)
+	}
+
+	if viper.GetBool("database.auto_migrate") {
+		if err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("Error migrating: %v", err)
+		}
+		return
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return err
+	if err := migrator.CheckCurrent(context.Background()); err != nil {
+		log.Fatalf("Error schema: %v (run `migrate up`)", err)
 	}
-	return nil
 }
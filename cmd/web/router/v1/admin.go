@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/in/http"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure/wiring"
+	"github.com/VanillaSkys/todo_fiber/internal/job"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"github.com/VanillaSkys/todo_fiber/internal/middleware"
+	"github.com/gofiber/fiber/v3"
+	"github.com/spf13/viper"
+)
+
+func SetupAdminRoutes(router fiber.Router, todoRepo repository.TodoRepository, todoCache cache.Cache, cacheWrite service.CacheWrite) {
+	checker := service.NewCacheChecker(todoRepo, todoCache, wiring.TodoCodec(), wiring.TodoCacheSchema(), wiring.TodoCacheTTL(), cacheWrite)
+	adminHttp := http.NewHttpAdmin(checker)
+
+	go job.RunCacheCheck(
+		context.Background(),
+		checker,
+		viper.GetDuration("cache.check.interval"),
+		viper.GetBool("cache.check.repair"),
+	)
+
+	go job.RunCacheNamespaces(
+		context.Background(),
+		service.NewCacheNamespaces(todoCache, wiring.TodoCodec(), wiring.TodoCacheSchema()),
+		viper.GetDuration("cache.gc.heartbeat"),
+		viper.GetDuration("cache.gc.interval"),
+	)
+
+	token := viper.GetString("admin.token")
+	if token == "" {
+		logger.Log.Warn("Admin routes disabled, admin.token is not set.")
+		return
+	}
+	admin := router.Group("/admin", middleware.RequireToken(token))
+
+	admin.Get("/cache/consistency", adminHttp.CheckCache)
+	admin.Post("/cache/consistency/repair", adminHttp.RepairCache)
+}
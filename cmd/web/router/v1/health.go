@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/in/http"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/gofiber/fiber/v3"
+)
+
+func SetupHealthRoutes(router fiber.Router, cacheHealth cache.Health, ready func() bool) {
+	healthHttp := http.NewHttpHealth(cacheHealth, ready)
+
+	router.Get("/health", healthHttp.Check)
+	router.Get("/ready", healthHttp.Ready)
+}
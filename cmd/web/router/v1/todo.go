@@ -1,26 +1,96 @@
 package v1
 
 import (
+	"context"
+	"sync/atomic"
+
 	"github.com/VanillaSkys/todo_fiber/internal/adapter/in/http"
-	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/postgres"
-	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/redis"
-	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/requestctx"
 	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
-	"github.com/VanillaSkys/todo_fiber/internal/infrastructure"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure/wiring"
+	"github.com/VanillaSkys/todo_fiber/internal/job"
 	"github.com/gofiber/fiber/v3"
+	"github.com/spf13/viper"
 )
 
-func SetupTodoRoutes(router fiber.Router) {
-	infrastructure.Db.AutoMigrate(dto.Todo{})
-	todoRepo := postgres.NewGormTodoRepository(infrastructure.Db)
-	todoCache := redis.NewRedisCache(infrastructure.RedisClient)
-	todoService := service.NewTodoService(todoRepo, todoCache)
+func SetupTodoRoutes(router fiber.Router, repositories wiring.Repositories, todoCache cache.Cache, cacheWrite service.CacheWrite, ready *atomic.Bool) {
+	todoCodec := wiring.TodoCodec()
+	todoSchema := wiring.TodoCacheSchema()
+	todoService := service.NewTodoService(
+		repositories.Todo,
+		repositories.History,
+		repositories.Outbox,
+		repositories.Transactor,
+		todoCache,
+		todoCodec,
+		todoSchema,
+		wiring.TodoCacheTTL(),
+		cacheWrite,
+		wiring.TodoSubtaskPolicy(),
+	)
 	todoHttp := http.NewHttpTodo(todoService)
+	outboxRelay := service.NewOutboxRelay(repositories.Outbox, repositories.Transactor, wiring.EventPublisher(), viper.GetInt("events.max_attempts"))
+
+	go job.RunTrashPurge(
+		requestctx.WithActor(context.Background(), requestctx.SystemActor),
+		todoService,
+		viper.GetDuration("todo.trash.retention"),
+		viper.GetDuration("todo.trash.purge_interval"),
+	)
+	go job.RunOutboxRelay(
+		context.Background(),
+		outboxRelay,
+		viper.GetDuration("events.relay_interval"),
+		viper.GetInt("events.batch_size"),
+	)
+	go job.RunCacheWarmUp(
+		context.Background(),
+		service.NewCacheWarmer(todoService),
+		viper.GetInt("cache.warmup.pages"),
+		viper.GetDuration("cache.warmup.timeout"),
+		ready,
+	)
+	go job.RunReminderDispatch(
+		context.Background(),
+		service.NewReminderDispatcher(repositories.Todo, repositories.Transactor, wiring.ReminderNotifier()),
+		viper.GetDuration("reminders.interval"),
+		viper.GetInt("reminders.batch_size"),
+	)
+	if cacheWrite.Strategy == service.CacheWriteBehind {
+		go job.RunWriteBehindFlush(
+			context.Background(),
+			service.NewWriteBehindFlusher(
+				repositories.Todo,
+				repositories.History,
+				repositories.Outbox,
+				repositories.Transactor,
+				cacheWrite.Buffer,
+				todoCache,
+				todoCodec,
+				todoSchema,
+				wiring.TodoCacheTTL(),
+			),
+			viper.GetDuration("cache.write.behind.flush_interval"),
+			viper.GetInt("cache.write.behind.batch_size"),
+		)
+	}
 
 	todo := router.Group("/todo")
 
 	todo.Get("/", todoHttp.FindAll)
+	todo.Get("/search", todoHttp.Search)
+	todo.Get("/trash", todoHttp.FindTrash)
+	todo.Get("/overdue", todoHttp.Overdue)
+	todo.Get("/due", todoHttp.Due)
+	todo.Get("/:id", todoHttp.FindById)
+	todo.Get("/:id/history", todoHttp.History)
+	todo.Get("/:id/children", todoHttp.Children)
 	todo.Post("/", todoHttp.Create)
+	todo.Post("/:id/restore", todoHttp.Restore)
+	todo.Post("/:id/move", todoHttp.Move)
 	todo.Put("/", todoHttp.Update)
+	todo.Put("/:id/due", todoHttp.Schedule)
 	todo.Delete("/", todoHttp.Delete)
+	todo.Delete("/trash/:id", todoHttp.Purge)
 }
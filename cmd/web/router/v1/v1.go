@@ -1,9 +1,21 @@
 package v1
 
-import "github.com/gofiber/fiber/v3"
+import (
+	"sync/atomic"
+
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure/wiring"
+	"github.com/gofiber/fiber/v3"
+)
 
 func SetupV1Routes(app fiber.Router) {
 	v1 := app.Group("/v1")
 
-	SetupTodoRoutes(v1)
+	repositories := wiring.TodoRepositories()
+	todoCache, cacheHealth := wiring.TodoCache()
+	cacheWrite := wiring.TodoCacheWrite()
+	ready := &atomic.Bool{}
+
+	SetupTodoRoutes(v1, repositories, todoCache, cacheWrite, ready)
+	SetupAdminRoutes(v1, repositories.Todo, todoCache, cacheWrite)
+	SetupHealthRoutes(v1, cacheHealth, ready.Load)
 }
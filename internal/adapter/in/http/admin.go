@@ -0,0 +1,41 @@
+package http
+
+import (
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+type httpAdminImpl struct {
+	checker service.CacheChecker
+}
+
+func NewHttpAdmin(checker service.CacheChecker) *httpAdminImpl {
+	return &httpAdminImpl{checker: checker}
+}
+
+func (h *httpAdminImpl) CheckCache(c fiber.Ctx) error {
+	return h.checkCache(c, false)
+}
+
+func (h *httpAdminImpl) RepairCache(c fiber.Ctx) error {
+	return h.checkCache(c, true)
+}
+
+func (h *httpAdminImpl) checkCache(c fiber.Ctx, repair bool) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to check cache consistency.", zap.Bool("repair", repair))
+	report, err := h.checker.Check(c.UserContext(), repair)
+	if err != nil {
+		httpLogger.Error("Error checking cache consistency", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check cache consistency",
+		})
+	}
+
+	httpLogger.Info("Returning cache consistency report.")
+	return c.JSON(fiber.Map{"message": report, "consistent": report.Consistent(), "X-Request-ID": requestId})
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+)
+
+var errInvalidIfMatch = errors.New("invalid If-Match header")
+
+func etag(todo dto.Todo) string {
+	return `"` + strconv.FormatInt(todo.Version, 10) + `"`
+}
+
+// parseIfMatch returns the version named by an If-Match header. An empty header or "*" returns zero.
+func parseIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/gofiber/fiber/v3"
+)
+
+type httpHealthImpl struct {
+	cache cache.Health
+	ready func() bool
+}
+
+// NewHttpHealth reports on cache, which may be nil when the cache cannot be bypassed, and on ready,
+// which turns true once the instance has warmed up.
+func NewHttpHealth(cache cache.Health, ready func() bool) *httpHealthImpl {
+	return &httpHealthImpl{cache: cache, ready: ready}
+}
+
+// Check answers 200 while the API can serve requests; a bypassed cache only degrades it.
+func (h *httpHealthImpl) Check(c fiber.Ctx) error {
+	status, cacheStatus := "ok", "ok"
+	if h.cache != nil && h.cache.Bypassed() {
+		status, cacheStatus = "degraded", "bypass"
+	}
+	return c.JSON(fiber.Map{"status": status, "cache": cacheStatus})
+}
+
+// Ready answers 503 until the cache warm-up has finished, so traffic reaches the instance warm.
+func (h *httpHealthImpl) Ready(c fiber.Ctx) error {
+	if !h.ready() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "warming"})
+	}
+	return c.JSON(fiber.Map{"status": "ready"})
+}
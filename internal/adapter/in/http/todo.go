@@ -1,6 +1,9 @@
 package http
 
 import (
+	"errors"
+	"time"
+
 	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
 	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
 	"github.com/VanillaSkys/todo_fiber/internal/logger"
@@ -16,7 +19,11 @@ type httpTodoImpl struct {
 }
 
 func NewHttpTodo(service service.TodoService) *httpTodoImpl {
-	return &httpTodoImpl{service: service, validator: validator.New()}
+	validate := validator.New()
+	validate.RegisterValidation("todostatus", func(field validator.FieldLevel) bool {
+		return dto.TodoStatus(field.Field().String()).Valid()
+	})
+	return &httpTodoImpl{service: service, validator: validate}
 }
 
 func (h *httpTodoImpl) FindAll(c fiber.Ctx) error {
@@ -24,8 +31,27 @@ func (h *httpTodoImpl) FindAll(c fiber.Ctx) error {
 	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
 
 	httpLogger.Info("Call interface to find all todos.")
-	todos, err := h.service.FindAll()
+	created, createdErr := parseTimeRange(c, "created")
+	updated, updatedErr := parseTimeRange(c, "updated")
+	completed, completedErr := parseTimeRange(c, "completed")
+	if err := errors.Join(createdErr, updatedErr, completedErr); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid time range, use RFC 3339 timestamps."})
+	}
+	query := dto.TodoQuery{
+		Limit:        fiber.Query[int](c, "limit"),
+		Cursor:       c.Query("cursor"),
+		Status:       dto.TodoStatus(c.Query("status")),
+		Created:      created,
+		Updated:      updated,
+		Completed:    completed,
+		Sort:         c.Query("sort"),
+		IncludeTotal: fiber.Query[bool](c, "include_total"),
+	}
+	page, err := h.service.FindAll(c.UserContext(), query)
 	if err != nil {
+		if errors.Is(err, dto.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit, cursor, status, time range or sort."})
+		}
 		httpLogger.Error("Error fetching todos from service", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch todos",
@@ -33,7 +59,56 @@ func (h *httpTodoImpl) FindAll(c fiber.Ctx) error {
 	}
 
 	httpLogger.Info("Returning todos.")
-	return c.JSON(fiber.Map{"message": todos, "X-Request-ID": requestId})
+	response := fiber.Map{"message": page.Todos, "next_cursor": page.NextCursor, "X-Request-ID": requestId}
+	if page.Total != nil {
+		response["total"] = *page.Total
+	}
+	return c.JSON(response)
+}
+
+func (h *httpTodoImpl) FindById(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to find todo by id.")
+	todo, err := h.service.FindById(c.UserContext(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found."})
+		}
+		httpLogger.Error("Error fetching todo from service", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch todo",
+		})
+	}
+
+	httpLogger.Info("Returning todo.")
+	c.Set(fiber.HeaderETag, etag(todo))
+	return c.JSON(fiber.Map{"message": todo, "X-Request-ID": requestId})
+}
+
+func (h *httpTodoImpl) Search(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to search todos.")
+	query := dto.TodoSearchQuery{
+		Text:  c.Query("q"),
+		Limit: fiber.Query[int](c, "limit"),
+	}
+	results, err := h.service.Search(c.UserContext(), query)
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Query parameter q is required and limit must be between 1 and 100."})
+		}
+		httpLogger.Error("Error searching todos from service", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search todos",
+		})
+	}
+
+	httpLogger.Info("Returning search results.")
+	return c.JSON(fiber.Map{"message": results, "X-Request-ID": requestId})
 }
 
 func (h *httpTodoImpl) Create(c fiber.Ctx) error {
@@ -52,16 +127,34 @@ func (h *httpTodoImpl) Create(c fiber.Ctx) error {
 	}
 	todo := dto.Todo{
 		Id:          uuid.NewString(),
+		ParentId:    input.ParentId,
 		Topic:       input.Topic,
 		Description: input.Description,
 		Status:      input.Status,
+		Priority:    input.Priority,
+		Due:         input.Due,
+		Reminders:   input.Reminders,
 	}
 
-	if err := h.service.Create(todo); err != nil {
+	todo, err := h.service.Create(c.UserContext(), todo)
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidStatus) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid status."})
+		}
+		if errors.Is(err, dto.ErrInvalidDue) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid due date or reminders."})
+		}
+		if errors.Is(err, dto.ErrInvalidPriority) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid priority."})
+		}
+		if errors.Is(err, dto.ErrInvalidParent) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid parent, it must be a live todo that is not done."})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Internal server error."})
 	}
 
 	httpLogger.Info("Todo created successfully.")
+	c.Set(fiber.HeaderETag, etag(todo))
 	return c.JSON(fiber.Map{
 		"message":   "insert ok",
 		"dataAdded": todo,
@@ -82,16 +175,176 @@ func (h *httpTodoImpl) Update(c fiber.Ctx) error {
 			return c.Status(400).JSON(fiber.Map{"error": "Validation error on field:" + e.StructField() + " - " + e.Tag()})
 		}
 	}
-	if err := h.service.Update(input); err != nil {
+	expectedVersion, err := parseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid If-Match header."})
+	}
+	input.ExpectedVersion = expectedVersion
+
+	todo, err := h.service.Update(c.UserContext(), input)
+	if err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found."})
+		}
+		if errors.Is(err, dto.ErrVersionConflict) {
+			return h.preconditionFailed(c, input.Id)
+		}
+		if errors.Is(err, dto.ErrInvalidStatus) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid status."})
+		}
+		var transition *dto.StatusTransitionError
+		if errors.As(err, &transition) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":   "Status transition not allowed.",
+				"from":    transition.From,
+				"to":      transition.To,
+				"allowed": transition.Allowed,
+			})
+		}
+		if errors.Is(err, dto.ErrOpenSubtasks) {
+			return openSubtasks(c, err)
+		}
+		if errors.Is(err, dto.ErrInvalidParent) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Parent todo is done, reopen it first."})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Internal server error."})
 	}
 
 	httpLogger.Info("Todo updated successfully.")
+	c.Set(fiber.HeaderETag, etag(todo))
+	return c.JSON(fiber.Map{
+		"message":     "update ok",
+		"dataUpdated": todo,
+	})
+}
+
+// Schedule sets or clears the due date and reminders of the todo named in the path.
+func (h *httpTodoImpl) Schedule(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to schedule todo.")
+	var input dto.TodoInputSchedule
+	if err := c.Bind().Body(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid due date or reminders."})
+	}
+	expectedVersion, err := parseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid If-Match header."})
+	}
+	input.Id = c.Params("id")
+	input.ExpectedVersion = expectedVersion
+
+	todo, err := h.service.Schedule(c.UserContext(), input)
+	if err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found."})
+		}
+		if errors.Is(err, dto.ErrVersionConflict) {
+			return h.preconditionFailed(c, input.Id)
+		}
+		if errors.Is(err, dto.ErrInvalidDue) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid due date or reminders."})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Internal server error."})
+	}
+
+	httpLogger.Info("Todo scheduled successfully.")
+	c.Set(fiber.HeaderETag, etag(todo))
+	return c.JSON(fiber.Map{
+		"message":       "schedule ok",
+		"dataScheduled": todo,
+	})
+}
+
+// Move places the todo named in the path between the todos named by after and before, the ids of
+// its new neighbours, giving it their priority. Without neighbours it moves to the end of priority.
+func (h *httpTodoImpl) Move(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to move todo.")
+	var input dto.TodoInputMove
+	if err := c.Bind().Body(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body."})
+	}
+	expectedVersion, err := parseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid If-Match header."})
+	}
+	input.Id = c.Params("id")
+	input.ExpectedVersion = expectedVersion
+
+	todo, err := h.service.Move(c.UserContext(), input)
+	if err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found."})
+		}
+		if errors.Is(err, dto.ErrVersionConflict) {
+			return h.preconditionFailed(c, input.Id)
+		}
+		if errors.Is(err, dto.ErrInvalidMove) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid move, after and before must name other todos of one priority in list order."})
+		}
+		if errors.Is(err, dto.ErrInvalidPriority) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid priority."})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Internal server error."})
+	}
+
+	httpLogger.Info("Todo moved successfully.")
+	c.Set(fiber.HeaderETag, etag(todo))
 	return c.JSON(fiber.Map{
-		"message": "update ok",
+		"message":   "move ok",
+		"dataMoved": todo,
 	})
 }
 
+func (h *httpTodoImpl) Overdue(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to find overdue todos.")
+	todos, err := h.service.Overdue(c.UserContext(), fiber.Query[int](c, "limit"))
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit."})
+		}
+		httpLogger.Error("Error fetching overdue todos from service", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch overdue todos",
+		})
+	}
+
+	httpLogger.Info("Returning overdue todos.")
+	return c.JSON(fiber.Map{"message": todos, "X-Request-ID": requestId})
+}
+
+// Due lists the todos falling due in the next ?within=, a duration such as 24h.
+func (h *httpTodoImpl) Due(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to find todos due soon.")
+	within, err := time.ParseDuration(c.Query("within", "24h"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid within, use a duration such as 24h."})
+	}
+	todos, err := h.service.DueWithin(c.UserContext(), within, fiber.Query[int](c, "limit"))
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid within or limit."})
+		}
+		httpLogger.Error("Error fetching due todos from service", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch due todos",
+		})
+	}
+
+	httpLogger.Info("Returning due todos.")
+	return c.JSON(fiber.Map{"message": todos, "X-Request-ID": requestId})
+}
+
 func (h *httpTodoImpl) Delete(c fiber.Ctx) error {
 	requestId := c.Locals("X-Request-ID").(string)
 	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
@@ -106,7 +359,22 @@ func (h *httpTodoImpl) Delete(c fiber.Ctx) error {
 			return c.Status(400).JSON(fiber.Map{"error": "Validation error on field:" + e.StructField() + " - " + e.Tag()})
 		}
 	}
-	if err := h.service.Delete(input); err != nil {
+	expectedVersion, err := parseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid If-Match header."})
+	}
+	input.ExpectedVersion = expectedVersion
+
+	if err := h.service.Delete(c.UserContext(), input); err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found."})
+		}
+		if errors.Is(err, dto.ErrVersionConflict) {
+			return h.preconditionFailed(c, input.Id)
+		}
+		if errors.Is(err, dto.ErrOpenSubtasks) {
+			return openSubtasks(c, err)
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Internal server error."})
 	}
 	httpLogger.Info("Todo deleted successfully.")
@@ -114,3 +382,154 @@ func (h *httpTodoImpl) Delete(c fiber.Ctx) error {
 		"message": "deleted ok",
 	})
 }
+
+// openSubtasks answers a write the subtask policy rejected with the ids of the open subtasks.
+func openSubtasks(c fiber.Ctx, err error) error {
+	var open *dto.OpenSubtasksError
+	errors.As(err, &open)
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"error":         "Todo has open subtasks.",
+		"open_subtasks": open.Open,
+	})
+}
+
+// Children lists the subtasks directly below the todo named in the path, or all of them with
+// ?recursive=true, each with its progress.
+func (h *httpTodoImpl) Children(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to find subtasks.")
+	todos, err := h.service.Children(c.UserContext(), c.Params("id"), fiber.Query[bool](c, "recursive"))
+	if err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found."})
+		}
+		httpLogger.Error("Error fetching subtasks from service", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch subtasks",
+		})
+	}
+
+	httpLogger.Info("Returning subtasks.")
+	return c.JSON(fiber.Map{"message": todos, "X-Request-ID": requestId})
+}
+
+func (h *httpTodoImpl) FindTrash(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to find trashed todos.")
+	todos, err := h.service.FindTrash(c.UserContext())
+	if err != nil {
+		httpLogger.Error("Error fetching trashed todos from service", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch trashed todos",
+		})
+	}
+
+	httpLogger.Info("Returning trashed todos.")
+	return c.JSON(fiber.Map{"message": todos, "X-Request-ID": requestId})
+}
+
+func (h *httpTodoImpl) Restore(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to restore todo.")
+	todo, err := h.service.Restore(c.UserContext(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found in trash."})
+		}
+		if errors.Is(err, dto.ErrInvalidParent) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Parent todo is trashed or done, restore or reopen it first."})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Internal server error."})
+	}
+
+	httpLogger.Info("Todo restored successfully.")
+	c.Set(fiber.HeaderETag, etag(todo))
+	return c.JSON(fiber.Map{
+		"message":      "restore ok",
+		"dataRestored": todo,
+	})
+}
+
+func (h *httpTodoImpl) Purge(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to purge todo.")
+	if err := h.service.Purge(c.UserContext(), c.Params("id")); err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found in trash."})
+		}
+		if errors.Is(err, dto.ErrOpenSubtasks) {
+			return openSubtasks(c, err)
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Internal server error."})
+	}
+
+	httpLogger.Info("Todo purged successfully.")
+	return c.JSON(fiber.Map{
+		"message": "purge ok",
+	})
+}
+
+func (h *httpTodoImpl) History(c fiber.Ctx) error {
+	requestId := c.Locals("X-Request-ID").(string)
+	httpLogger := logger.Log.With(zap.String("X-Request-ID", requestId))
+
+	httpLogger.Info("Call interface to find todo history.")
+	entries, err := h.service.History(c.UserContext(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found."})
+		}
+		httpLogger.Error("Error fetching todo history from service", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch todo history",
+		})
+	}
+
+	httpLogger.Info("Returning todo history.")
+	return c.JSON(fiber.Map{"message": entries, "X-Request-ID": requestId})
+}
+
+// preconditionFailed answers a stale If-Match with the current representation of the todo.
+func (h *httpTodoImpl) preconditionFailed(c fiber.Ctx, id string) error {
+	current, err := h.service.FindById(c.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Todo not found."})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Internal server error."})
+	}
+
+	c.Set(fiber.HeaderETag, etag(current))
+	return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+		"error":   "Todo has been modified.",
+		"current": current,
+	})
+}
+
+// parseTimeRange reads the <name>_after and <name>_before query parameters as RFC 3339 timestamps.
+func parseTimeRange(c fiber.Ctx, name string) (dto.TimeRange, error) {
+	var r dto.TimeRange
+	for _, bound := range []struct {
+		param string
+		value *time.Time
+	}{{name + "_after", &r.After}, {name + "_before", &r.Before}} {
+		raw := c.Query(bound.param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return dto.TimeRange{}, err
+		}
+		*bound.value = parsed
+	}
+	return r, nil
+}
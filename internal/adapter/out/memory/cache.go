@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+)
+
+type memoryCacheEntry struct {
+	value     string
+	version   int64
+	expiresAt time.Time
+}
+
+func (e memoryCacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// expiry turns a ttl into an absolute deadline; zero means the entry never expires.
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+type memoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]memoryCacheEntry
+}
+
+func NewMemoryCache() cache.Cache {
+	return &memoryCache{entries: map[string]memoryCacheEntry{}}
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	m.mu.RLock()
+	entry, ok := m.entries[key]
+	m.mu.RUnlock()
+
+	if !ok || entry.expired(time.Now()) {
+		return "", cache.ErrCacheMiss
+	}
+	return entry.value, nil
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return m.MSet(ctx, map[string]string{key: value}, ttl)
+}
+
+func (m *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry, ok := m.entries[key]
+	return ok && !entry.expired(time.Now()), nil
+}
+
+func (m *memoryCache) MSet(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	expiresAt := expiry(ttl)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evictExpired()
+	for key, value := range values {
+		m.entries[key] = memoryCacheEntry{value: value, expiresAt: expiresAt}
+	}
+	return nil
+}
+
+func (m *memoryCache) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	values := map[string]string{}
+	now := time.Now()
+	for _, key := range keys {
+		entry, ok := m.entries[key]
+		if ok && !entry.expired(now) {
+			values[key] = entry.value
+		}
+	}
+	return values, nil
+}
+
+func (m *memoryCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []string{}
+	now := time.Now()
+	for key, entry := range m.entries {
+		if strings.HasPrefix(key, prefix) && !entry.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memoryCache) SetIfNewer(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entries := make(map[string]memoryCacheEntry, len(values))
+	for key, value := range values {
+		version, err := cache.ValueVersion(value)
+		if err != nil {
+			return err
+		}
+		entries[key] = memoryCacheEntry{value: value, version: version, expiresAt: expiry(ttl)}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evictExpired()
+	for key, entry := range entries {
+		if current, ok := m.entries[key]; ok && current.version > entry.version {
+			continue
+		}
+		m.entries[key] = entry
+	}
+	return nil
+}
+
+func (m *memoryCache) Del(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// evictExpired drops expired entries so stale list pages do not accumulate. Callers hold mu.
+func (m *memoryCache) evictExpired() {
+	now := time.Now()
+	for key, entry := range m.entries {
+		if entry.expired(now) {
+			delete(m.entries, key)
+		}
+	}
+}
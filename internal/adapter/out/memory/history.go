@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+)
+
+type memoryTodoHistoryRepositoryImpl struct {
+	mu      sync.RWMutex
+	entries []dto.TodoHistory
+}
+
+func NewMemoryTodoHistoryRepository() repository.TodoHistoryRepository {
+	return &memoryTodoHistoryRepositoryImpl{}
+}
+
+func (m *memoryTodoHistoryRepositoryImpl) Append(ctx context.Context, entry dto.TodoHistory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry.Id = int64(len(m.entries) + 1)
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryTodoHistoryRepositoryImpl) FindByTodoId(ctx context.Context, todoId string) ([]dto.TodoHistory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := []dto.TodoHistory{}
+	for _, entry := range m.entries {
+		if entry.TodoId == todoId {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
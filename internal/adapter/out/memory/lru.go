@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+)
+
+type lruEntry struct {
+	key string
+	memoryCacheEntry
+}
+
+// lruCache is a bounded cache that evicts the least recently used key once it holds size entries.
+// Every entry expires after at most ttl, which bounds how stale it can get when used as an L1.
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	order *list.List
+}
+
+func NewLRUCache(size int, ttl time.Duration) cache.Cache {
+	return &lruCache{
+		size:  size,
+		ttl:   ttl,
+		items: map[string]*list.Element{},
+		order: list.New(),
+	}
+}
+
+func (l *lruCache) Get(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.lookup(key, time.Now())
+	if !ok {
+		return "", cache.ErrCacheMiss
+	}
+	return entry.value, nil
+}
+
+func (l *lruCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return l.MSet(ctx, map[string]string{key: value}, ttl)
+}
+
+func (l *lruCache) Del(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		l.remove(key)
+	}
+	return nil
+}
+
+func (l *lruCache) Exists(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.lookup(key, time.Now())
+	return ok, nil
+}
+
+func (l *lruCache) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	values := map[string]string{}
+	now := time.Now()
+	for _, key := range keys {
+		if entry, ok := l.lookup(key, now); ok {
+			values[key] = entry.value
+		}
+	}
+	return values, nil
+}
+
+func (l *lruCache) MSet(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	expiresAt := expiry(l.bound(ttl))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, value := range values {
+		l.store(key, memoryCacheEntry{value: value, expiresAt: expiresAt})
+	}
+	return nil
+}
+
+func (l *lruCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := []string{}
+	now := time.Now()
+	for key, element := range l.items {
+		if strings.HasPrefix(key, prefix) && !element.Value.(*lruEntry).expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (l *lruCache) SetIfNewer(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	expiresAt := expiry(l.bound(ttl))
+	entries := make(map[string]memoryCacheEntry, len(values))
+	for key, value := range values {
+		version, err := cache.ValueVersion(value)
+		if err != nil {
+			return err
+		}
+		entries[key] = memoryCacheEntry{value: value, version: version, expiresAt: expiresAt}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	for key, entry := range entries {
+		if current, ok := l.lookup(key, now); ok && current.version > entry.version {
+			continue
+		}
+		l.store(key, entry)
+	}
+	return nil
+}
+
+// bound caps ttl at the cache's own ttl.
+func (l *lruCache) bound(ttl time.Duration) time.Duration {
+	if ttl <= 0 || (l.ttl > 0 && ttl > l.ttl) {
+		return l.ttl
+	}
+	return ttl
+}
+
+// lookup returns a live entry and marks it as recently used. Callers hold mu.
+func (l *lruCache) lookup(key string, now time.Time) (memoryCacheEntry, bool) {
+	element, ok := l.items[key]
+	if !ok {
+		return memoryCacheEntry{}, false
+	}
+	entry := element.Value.(*lruEntry)
+	if entry.expired(now) {
+		l.remove(key)
+		return memoryCacheEntry{}, false
+	}
+	l.order.MoveToFront(element)
+	return entry.memoryCacheEntry, true
+}
+
+// store inserts or replaces key, evicting the least recently used entry when full. Callers hold mu.
+func (l *lruCache) store(key string, entry memoryCacheEntry) {
+	if element, ok := l.items[key]; ok {
+		element.Value.(*lruEntry).memoryCacheEntry = entry
+		l.order.MoveToFront(element)
+		return
+	}
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, memoryCacheEntry: entry})
+	for l.size > 0 && l.order.Len() > l.size {
+		l.remove(l.order.Back().Value.(*lruEntry).key)
+	}
+}
+
+// remove drops key. Callers hold mu.
+func (l *lruCache) remove(key string) {
+	if element, ok := l.items[key]; ok {
+		l.order.Remove(element)
+		delete(l.items, key)
+	}
+}
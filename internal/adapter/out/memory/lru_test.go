@@ -0,0 +1,123 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/memory"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCacheEviction(t *testing.T) {
+	testCases := []struct {
+		description string
+		act         func(ctx context.Context, lru cache.Cache)
+		expected    map[string]string
+	}{
+		{
+			description: "The least recently written key is evicted when full.",
+			act: func(ctx context.Context, lru cache.Cache) {
+				_ = lru.Set(ctx, "c", "3", 0)
+			},
+			expected: map[string]string{"b": "2", "c": "3"},
+		},
+		{
+			description: "A read makes a key recently used.",
+			act: func(ctx context.Context, lru cache.Cache) {
+				_, _ = lru.Get(ctx, "a")
+				_ = lru.Set(ctx, "c", "3", 0)
+			},
+			expected: map[string]string{"a": "1", "c": "3"},
+		},
+		{
+			description: "Overwriting a key makes it recently used without growing the cache.",
+			act: func(ctx context.Context, lru cache.Cache) {
+				_ = lru.Set(ctx, "a", "4", 0)
+				_ = lru.Set(ctx, "c", "3", 0)
+			},
+			expected: map[string]string{"a": "4", "c": "3"},
+		},
+		{
+			description: "A deleted key frees its slot.",
+			act: func(ctx context.Context, lru cache.Cache) {
+				_ = lru.Del(ctx, "a")
+				_ = lru.Set(ctx, "c", "3", 0)
+			},
+			expected: map[string]string{"b": "2", "c": "3"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			lru := memory.NewLRUCache(2, time.Hour)
+			assert.NoError(t, lru.Set(ctx, "a", "1", 0))
+			assert.NoError(t, lru.Set(ctx, "b", "2", 0))
+
+			// Act
+			testCase.act(ctx, lru)
+
+			// Assert
+			values, err := lru.MGet(ctx, []string{"a", "b", "c"})
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, values)
+		})
+	}
+}
+
+func TestLRUCacheExpiry(t *testing.T) {
+	testCases := []struct {
+		description string
+		cacheTTL    time.Duration
+		ttl         time.Duration
+		expired     bool
+	}{
+		{description: "An entry expires after its own ttl.", cacheTTL: time.Hour, ttl: 20 * time.Millisecond, expired: true},
+		{description: "An entry without a ttl expires after the cache's.", cacheTTL: 20 * time.Millisecond, expired: true},
+		{description: "A longer ttl is capped at the cache's.", cacheTTL: 20 * time.Millisecond, ttl: time.Hour, expired: true},
+		{description: "An entry is served until it expires.", cacheTTL: time.Hour, ttl: time.Hour},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			lru := memory.NewLRUCache(10, testCase.cacheTTL)
+			assert.NoError(t, lru.Set(ctx, "a", "1", testCase.ttl))
+
+			// Act
+			time.Sleep(50 * time.Millisecond)
+			value, err := lru.Get(ctx, "a")
+
+			// Assert
+			if testCase.expired {
+				assert.ErrorIs(t, err, cache.ErrCacheMiss)
+				keys, err := lru.Scan(ctx, "")
+				assert.NoError(t, err)
+				assert.Empty(t, keys)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "1", value)
+		})
+	}
+}
+
+func TestLRUCacheSetIfNewer(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	lru := memory.NewLRUCache(10, time.Hour)
+	assert.NoError(t, lru.SetIfNewer(ctx, map[string]string{"a": cache.Versioned(2, []byte("new"))}, 0))
+
+	// Act
+	err := lru.SetIfNewer(ctx, map[string]string{"a": cache.Versioned(1, []byte("old")), "b": cache.Versioned(1, []byte("b"))}, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	values, err := lru.MGet(ctx, []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "2:new", "b": "1:b"}, values)
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+)
+
+type memoryOutboxRepositoryImpl struct {
+	mu     sync.RWMutex
+	events []dto.OutboxEvent
+}
+
+func NewMemoryOutboxRepository() repository.OutboxRepository {
+	return &memoryOutboxRepositoryImpl{}
+}
+
+func (m *memoryOutboxRepositoryImpl) Append(ctx context.Context, event dto.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *memoryOutboxRepositoryImpl) FindUnpublished(ctx context.Context, limit int, maxAttempts int) ([]dto.OutboxEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := []dto.OutboxEvent{}
+	for _, event := range m.events {
+		if maxAttempts > 0 && event.Attempts >= maxAttempts {
+			continue
+		}
+		if event.PublishedAt == nil && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// MarkPublished drops published events, since nothing reads them back from memory.
+func (m *memoryOutboxRepositoryImpl) MarkPublished(ctx context.Context, ids []string, publishedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = slices.DeleteFunc(m.events, func(event dto.OutboxEvent) bool {
+		return slices.Contains(ids, event.Id)
+	})
+	return nil
+}
+
+func (m *memoryOutboxRepositoryImpl) MarkFailed(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for index := range m.events {
+		if m.events[index].Id == id {
+			m.events[index].Attempts++
+		}
+	}
+	return nil
+}
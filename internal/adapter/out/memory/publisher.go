@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/event"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+// logPublisher writes events to the application log, for installs without a message broker.
+type logPublisher struct{}
+
+func NewLogPublisher() event.Publisher {
+	return &logPublisher{}
+}
+
+func (l *logPublisher) Publish(ctx context.Context, event dto.OutboxEvent) error {
+	logger.Log.Info("Todo event published.",
+		zap.String("event_id", event.Id),
+		zap.String("event_type", event.EventType),
+		zap.String("aggregate_id", event.AggregateId),
+		zap.String("payload", event.Payload),
+	)
+	return nil
+}
@@ -0,0 +1,459 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"gorm.io/gorm"
+)
+
+type memoryTodoRepositoryImpl struct {
+	mu    sync.RWMutex
+	todos map[string]dto.Todo
+}
+
+func NewMemoryTodoRepository() repository.TodoRepository {
+	return &memoryTodoRepositoryImpl{todos: map[string]dto.Todo{}}
+}
+
+func (m *memoryTodoRepositoryImpl) FindAll(ctx context.Context, query dto.TodoQuery) (dto.TodoPage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	todos := []dto.Todo{}
+	for _, todo := range m.todos {
+		if query.Matches(todo) {
+			todos = append(todos, todo)
+		}
+	}
+
+	var page dto.TodoPage
+	if query.IncludeTotal {
+		total := int64(len(todos))
+		page.Total = &total
+	}
+
+	slices.SortFunc(todos, query.Compare)
+
+	if query.Cursor != "" {
+		cursor, err := dto.DecodeTodoCursor(query.Sort, query.Cursor)
+		if err != nil {
+			return dto.TodoPage{}, err
+		}
+		start := len(todos)
+		for index, todo := range todos {
+			if query.After(todo, cursor) {
+				start = index
+				break
+			}
+		}
+		todos = todos[start:]
+	}
+
+	if len(todos) > query.Limit {
+		todos = todos[:query.Limit]
+		nextCursor, err := dto.EncodeTodoCursor(query.Sort, todos[len(todos)-1])
+		if err != nil {
+			return dto.TodoPage{}, err
+		}
+		page.NextCursor = nextCursor
+	}
+	page.Todos = todos
+
+	return page, nil
+}
+
+func (m *memoryTodoRepositoryImpl) FindById(ctx context.Context, id string) (dto.Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	todo, ok := m.todos[id]
+	if !ok || todo.DeletedAt.Valid {
+		return dto.Todo{}, dto.ErrTodoNotFound
+	}
+	return todo, nil
+}
+
+func (m *memoryTodoRepositoryImpl) FindByIdForUpdate(ctx context.Context, id string) (dto.Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	todo, ok := m.todos[id]
+	if !ok {
+		return dto.Todo{}, dto.ErrTodoNotFound
+	}
+	return todo, nil
+}
+
+func (m *memoryTodoRepositoryImpl) Search(ctx context.Context, query dto.TodoSearchQuery) ([]dto.TodoSearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := []dto.TodoSearchResult{}
+	for _, todo := range m.todos {
+		if todo.DeletedAt.Valid {
+			continue
+		}
+		if result, ok := query.Match(todo); ok {
+			results = append(results, result)
+		}
+	}
+
+	slices.SortFunc(results, func(a, b dto.TodoSearchResult) int {
+		if order := cmp.Compare(b.Rank, a.Rank); order != 0 {
+			return order
+		}
+		return cmp.Compare(a.Todo.Id, b.Todo.Id)
+	})
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
+func (m *memoryTodoRepositoryImpl) FindChildren(ctx context.Context, parentId string) ([]dto.Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.children(parentId), nil
+}
+
+func (m *memoryTodoRepositoryImpl) FindSubtree(ctx context.Context, rootId string) ([]dto.Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.subtree(rootId), nil
+}
+
+func (m *memoryTodoRepositoryImpl) Progress(ctx context.Context, ids []string) (map[string]dto.TodoProgress, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	progress := map[string]dto.TodoProgress{}
+	for _, id := range ids {
+		subtree := m.subtree(id)
+		if len(subtree) == 0 {
+			continue
+		}
+		done := 0
+		for _, todo := range subtree {
+			if todo.Status == dto.TodoStatusDone {
+				done++
+			}
+		}
+		progress[id] = dto.NewTodoProgress(done, len(subtree))
+	}
+	return progress, nil
+}
+
+// children returns the live todos directly below parentId in rank order. Callers hold mu.
+func (m *memoryTodoRepositoryImpl) children(parentId string) []dto.Todo {
+	todos := []dto.Todo{}
+	for _, todo := range m.todos {
+		if !todo.DeletedAt.Valid && todo.ParentId != nil && *todo.ParentId == parentId {
+			todos = append(todos, todo)
+		}
+	}
+	slices.SortFunc(todos, dto.TodoQuery{Sort: dto.DefaultTodoSort}.Compare)
+	return todos
+}
+
+// subtree returns the live todos below rootId level by level. Callers hold mu.
+func (m *memoryTodoRepositoryImpl) subtree(rootId string) []dto.Todo {
+	todos := []dto.Todo{}
+	level := m.children(rootId)
+	for len(level) > 0 {
+		todos = append(todos, level...)
+		var next []dto.Todo
+		for _, todo := range level {
+			next = append(next, m.children(todo.Id)...)
+		}
+		slices.SortFunc(next, dto.TodoQuery{Sort: dto.DefaultTodoSort}.Compare)
+		level = next
+	}
+	return todos
+}
+
+func (m *memoryTodoRepositoryImpl) Save(ctx context.Context, input dto.Todo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.todos[input.Id]; exists {
+		return gorm.ErrDuplicatedKey
+	}
+	if input.Version == 0 {
+		input.Version = 1
+	}
+	if input.CreatedAt.IsZero() {
+		input.Stamp(time.Now())
+	}
+	input.NextReminderAt = input.NextReminder(time.Now())
+	m.todos[input.Id] = input
+	return nil
+}
+
+func (m *memoryTodoRepositoryImpl) Update(ctx context.Context, input dto.TodoInputUpdateStatus) (dto.Todo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, err := m.active(input.Id, input.ExpectedVersion)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	todo.Status = input.Status
+	todo.Stamp(time.Now())
+	todo.Version++
+	m.todos[todo.Id] = todo
+	return todo, nil
+}
+
+func (m *memoryTodoRepositoryImpl) Schedule(ctx context.Context, input dto.TodoInputSchedule) (dto.Todo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, err := m.active(input.Id, input.ExpectedVersion)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	now := time.Now()
+	todo.Due, todo.Reminders = input.Due, input.Reminders
+	todo.NextReminderAt = todo.NextReminder(now)
+	todo.Stamp(now)
+	todo.Version++
+	m.todos[todo.Id] = todo
+	return todo, nil
+}
+
+func (m *memoryTodoRepositoryImpl) Move(ctx context.Context, input dto.TodoInputMove) (dto.Todo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, err := m.active(input.Id, input.ExpectedVersion)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	todo.Position = input.Position
+	if input.Priority != nil {
+		todo.Priority = *input.Priority
+	}
+	todo.Stamp(time.Now())
+	todo.Version++
+	m.todos[todo.Id] = todo
+	return todo, nil
+}
+
+func (m *memoryTodoRepositoryImpl) LastPosition(ctx context.Context) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	last := ""
+	for _, todo := range m.todos {
+		if !todo.DeletedAt.Valid && todo.Position > last {
+			last = todo.Position
+		}
+	}
+	return last, nil
+}
+
+func (m *memoryTodoRepositoryImpl) AdjacentPosition(ctx context.Context, priority dto.TodoPriority, position string, after bool) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	adjacent := ""
+	for _, todo := range m.todos {
+		if todo.DeletedAt.Valid || todo.Priority != priority {
+			continue
+		}
+		if after && todo.Position > position && (adjacent == "" || todo.Position < adjacent) {
+			adjacent = todo.Position
+		}
+		if !after && todo.Position < position && todo.Position > adjacent {
+			adjacent = todo.Position
+		}
+	}
+	return adjacent, nil
+}
+
+func (m *memoryTodoRepositoryImpl) Delete(ctx context.Context, input dto.TodoInputDelete) (dto.Todo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, err := m.active(input.Id, input.ExpectedVersion)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	now := time.Now()
+	todo.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	todo.Stamp(now)
+	todo.Version++
+	m.todos[todo.Id] = todo
+	return todo, nil
+}
+
+func (m *memoryTodoRepositoryImpl) FindTrash(ctx context.Context) ([]dto.Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	todos := []dto.Todo{}
+	for _, todo := range m.todos {
+		if todo.DeletedAt.Valid {
+			todos = append(todos, todo)
+		}
+	}
+	slices.SortFunc(todos, func(a, b dto.Todo) int {
+		return b.DeletedAt.Time.Compare(a.DeletedAt.Time)
+	})
+	return todos, nil
+}
+
+func (m *memoryTodoRepositoryImpl) Restore(ctx context.Context, id string) (dto.Todo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, ok := m.todos[id]
+	if !ok || !todo.DeletedAt.Valid {
+		return dto.Todo{}, dto.ErrTodoNotFound
+	}
+	todo.DeletedAt = gorm.DeletedAt{}
+	todo.Stamp(time.Now())
+	todo.Version++
+	m.todos[id] = todo
+	return todo, nil
+}
+
+func (m *memoryTodoRepositoryImpl) Purge(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, ok := m.todos[id]
+	if !ok || !todo.DeletedAt.Valid {
+		return dto.ErrTodoNotFound
+	}
+	var subtasks []string
+	for _, subtask := range m.todos {
+		if subtask.ParentId != nil && *subtask.ParentId == id {
+			subtasks = append(subtasks, subtask.Id)
+		}
+	}
+	if len(subtasks) > 0 {
+		slices.Sort(subtasks)
+		return &dto.OpenSubtasksError{TodoId: id, Open: subtasks}
+	}
+	delete(m.todos, id)
+	return nil
+}
+
+func (m *memoryTodoRepositoryImpl) PurgeDeletedBefore(ctx context.Context, before time.Time) ([]dto.Todo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expired := func(todo dto.Todo) bool {
+		return todo.DeletedAt.Valid && todo.DeletedAt.Time.Before(before)
+	}
+	// A todo that stays keeps every todo above it.
+	kept := map[string]bool{}
+	for _, todo := range m.todos {
+		if expired(todo) {
+			continue
+		}
+		for parentId := todo.ParentId; parentId != nil && !kept[*parentId]; parentId = m.todos[*parentId].ParentId {
+			kept[*parentId] = true
+		}
+	}
+
+	purged := []dto.Todo{}
+	for id, todo := range m.todos {
+		if expired(todo) && !kept[id] {
+			purged = append(purged, todo)
+		}
+	}
+	for _, todo := range purged {
+		delete(m.todos, todo.Id)
+	}
+	return purged, nil
+}
+
+func (m *memoryTodoRepositoryImpl) WriteNext(ctx context.Context, todo dto.Todo) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.todos[todo.Id].Version
+	if stored != todo.Version-1 {
+		return stored, nil
+	}
+	todo.NextReminderAt = todo.NextReminder(time.Now())
+	m.todos[todo.Id] = todo
+	return stored, nil
+}
+
+func (m *memoryTodoRepositoryImpl) FindDue(ctx context.Context, r dto.TimeRange, limit int) ([]dto.Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	todos := []dto.Todo{}
+	for _, todo := range m.todos {
+		if !todo.DeletedAt.Valid && todo.Status != dto.TodoStatusDone && todo.Due.At != nil && r.Contains(*todo.Due.At) {
+			todos = append(todos, todo)
+		}
+	}
+	slices.SortFunc(todos, func(a, b dto.Todo) int {
+		if order := a.Due.At.Compare(*b.Due.At); order != 0 {
+			return order
+		}
+		return cmp.Compare(a.Id, b.Id)
+	})
+	if len(todos) > limit {
+		todos = todos[:limit]
+	}
+	return todos, nil
+}
+
+// FindRemindersDue takes no locks beyond the call; a single process runs one dispatcher.
+func (m *memoryTodoRepositoryImpl) FindRemindersDue(ctx context.Context, now time.Time, limit int) ([]dto.Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	todos := []dto.Todo{}
+	for _, todo := range m.todos {
+		if !todo.DeletedAt.Valid && todo.Status != dto.TodoStatusDone && todo.NextReminderAt != nil && !todo.NextReminderAt.After(now) {
+			todos = append(todos, todo)
+		}
+	}
+	slices.SortFunc(todos, func(a, b dto.Todo) int {
+		return a.NextReminderAt.Compare(*b.NextReminderAt)
+	})
+	if len(todos) > limit {
+		todos = todos[:limit]
+	}
+	return todos, nil
+}
+
+func (m *memoryTodoRepositoryImpl) SetNextReminder(ctx context.Context, id string, next *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, ok := m.todos[id]
+	if !ok {
+		return dto.ErrTodoNotFound
+	}
+	todo.NextReminderAt = next
+	m.todos[id] = todo
+	return nil
+}
+
+// active returns a live todo, checking its version when expectedVersion is set. Callers hold mu.
+func (m *memoryTodoRepositoryImpl) active(id string, expectedVersion int64) (dto.Todo, error) {
+	todo, ok := m.todos[id]
+	if !ok || todo.DeletedAt.Valid {
+		return dto.Todo{}, dto.ErrTodoNotFound
+	}
+	if expectedVersion != 0 && todo.Version != expectedVersion {
+		return dto.Todo{}, dto.ErrVersionConflict
+	}
+	return todo, nil
+}
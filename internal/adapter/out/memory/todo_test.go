@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/memory"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository/repositorytest"
+)
+
+func TestMemoryTodoRepository(t *testing.T) {
+	repositorytest.TestTodoRepository(t, func(t *testing.T) (repository.TodoRepository, repository.Transactor) {
+		return memory.NewMemoryTodoRepository(), memory.NewMemoryTransactor()
+	})
+}
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+)
+
+type txContextKey struct{}
+
+// memoryTransactorImpl serializes transactions instead of isolating them; nothing is rolled back.
+type memoryTransactorImpl struct {
+	mu sync.Mutex
+}
+
+func NewMemoryTransactor() repository.Transactor {
+	return &memoryTransactorImpl{}
+}
+
+func (m *memoryTransactorImpl) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txContextKey{}) != nil {
+		return fn(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn(context.WithValue(ctx, txContextKey{}, true))
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+)
+
+type memoryBufferedWrite struct {
+	write        dto.BufferedWrite
+	claimedUntil time.Time
+}
+
+type memoryWriteBuffer struct {
+	mu           sync.Mutex
+	writes       []memoryBufferedWrite
+	nextId       int64
+	claimTimeout time.Duration
+}
+
+// NewMemoryWriteBuffer buffers writes in process. Nothing survives a restart, so it only suits
+// development against the in-memory cache.
+func NewMemoryWriteBuffer(claimTimeout time.Duration) cache.WriteBuffer {
+	return &memoryWriteBuffer{claimTimeout: claimTimeout}
+}
+
+func (m *memoryWriteBuffer) Append(ctx context.Context, write dto.BufferedWrite) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextId++
+	write.Id = strconv.FormatInt(m.nextId, 10)
+	m.writes = append(m.writes, memoryBufferedWrite{write: write})
+	return nil
+}
+
+// Claim hands out a todo's writes only while none of its earlier writes is still claimed, so they
+// reach the flusher in order.
+func (m *memoryWriteBuffer) Claim(ctx context.Context, limit int) ([]dto.BufferedWrite, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	writes := []dto.BufferedWrite{}
+	held := map[string]bool{}
+	for index := range m.writes {
+		if len(writes) == limit {
+			break
+		}
+		id := m.writes[index].write.Todo.Id
+		if held[id] || now.Before(m.writes[index].claimedUntil) {
+			held[id] = true
+			continue
+		}
+		m.writes[index].claimedUntil = now.Add(m.claimTimeout)
+		writes = append(writes, m.writes[index].write)
+	}
+	return writes, nil
+}
+
+func (m *memoryWriteBuffer) Ack(ctx context.Context, ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.writes = slices.DeleteFunc(m.writes, func(buffered memoryBufferedWrite) bool {
+		return slices.Contains(ids, buffered.write.Id)
+	})
+	return nil
+}
@@ -0,0 +1,28 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/event"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/notify"
+)
+
+// eventNotifier publishes reminders as TodoReminderDue events next to the other todo events, for
+// consumers that deliver them by mail, chat or push.
+type eventNotifier struct {
+	publisher event.Publisher
+}
+
+func NewEventNotifier(publisher event.Publisher) notify.Notifier {
+	return &eventNotifier{publisher: publisher}
+}
+
+func (e *eventNotifier) Notify(ctx context.Context, reminder dto.TodoReminderDue) error {
+	event, err := dto.NewOutboxEvent(reminder, time.Now())
+	if err != nil {
+		return err
+	}
+	return e.publisher.Publish(ctx, event)
+}
@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/notify"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+// logNotifier writes reminders to the application log, for installs without a notification channel.
+type logNotifier struct{}
+
+func NewLogNotifier() notify.Notifier {
+	return &logNotifier{}
+}
+
+func (l *logNotifier) Notify(ctx context.Context, reminder dto.TodoReminderDue) error {
+	logger.Log.Info("Todo reminder due.",
+		zap.String("todo_id", reminder.TodoId),
+		zap.String("topic", reminder.Topic),
+		zap.String("due_at", reminder.Due.String()),
+		zap.String("offset", reminder.Offset),
+	)
+	return nil
+}
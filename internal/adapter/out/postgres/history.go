@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"gorm.io/gorm"
+)
+
+type gormTodoHistoryRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewGormTodoHistoryRepository(db *gorm.DB) repository.TodoHistoryRepository {
+	return &gormTodoHistoryRepositoryImpl{db: db}
+}
+
+func (g *gormTodoHistoryRepositoryImpl) Append(ctx context.Context, entry dto.TodoHistory) error {
+	return conn(ctx, g.db).Create(&entry).Error
+}
+
+func (g *gormTodoHistoryRepositoryImpl) FindByTodoId(ctx context.Context, todoId string) ([]dto.TodoHistory, error) {
+	entries := []dto.TodoHistory{}
+	result := conn(ctx, g.db).Where("todo_id = ?", todoId).Order("id").Find(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entries, nil
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormOutboxRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewGormOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &gormOutboxRepositoryImpl{db: db}
+}
+
+func (g *gormOutboxRepositoryImpl) Append(ctx context.Context, event dto.OutboxEvent) error {
+	return conn(ctx, g.db).Create(&event).Error
+}
+
+// FindUnpublished skips rows claimed by another relay so replicas can drain the outbox in parallel.
+func (g *gormOutboxRepositoryImpl) FindUnpublished(ctx context.Context, limit int, maxAttempts int) ([]dto.OutboxEvent, error) {
+	events := []dto.OutboxEvent{}
+	db := conn(ctx, g.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL")
+	if maxAttempts > 0 {
+		db = db.Where("attempts < ?", maxAttempts)
+	}
+	result := db.
+		Order("occurred_at").
+		Limit(limit).
+		Find(&events)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return events, nil
+}
+
+func (g *gormOutboxRepositoryImpl) MarkPublished(ctx context.Context, ids []string, publishedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return conn(ctx, g.db).Model(&dto.OutboxEvent{}).Where("id IN ?", ids).Update("published_at", publishedAt).Error
+}
+
+func (g *gormOutboxRepositoryImpl) MarkFailed(ctx context.Context, id string) error {
+	return conn(ctx, g.db).Model(&dto.OutboxEvent{}).Where("id = ?", id).Update("attempts", gorm.Expr("attempts + 1")).Error
+}
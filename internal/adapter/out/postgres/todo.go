@@ -1,9 +1,17 @@
 package postgres
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
 	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type gormTodoRepositoryImpl struct {
@@ -14,32 +22,525 @@ func NewGormTodoRepository(db *gorm.DB) repository.TodoRepository {
 	return &gormTodoRepositoryImpl{db: db}
 }
 
-func (g *gormTodoRepositoryImpl) FindAll() ([]dto.Todo, error) {
-	var todos []dto.Todo
-	result := g.db.Find(&todos)
+// FindAll returns one page of todos for a normalized query using keyset pagination on the sort field and id.
+func (g *gormTodoRepositoryImpl) FindAll(ctx context.Context, query dto.TodoQuery) (dto.TodoPage, error) {
+	var page dto.TodoPage
+	if query.IncludeTotal {
+		var total int64
+		if err := g.filter(ctx, query).Count(&total).Error; err != nil {
+			return dto.TodoPage{}, err
+		}
+		page.Total = &total
+	}
+
+	field, desc := query.SortField()
+	direction, comparison := "ASC", ">"
+	if desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	db := g.filter(ctx, query)
+	if query.Cursor != "" {
+		cursor, err := dto.DecodeTodoCursor(query.Sort, query.Cursor)
+		if err != nil {
+			return dto.TodoPage{}, err
+		}
+		db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", field.Column, comparison), cursor.Value, cursor.Id)
+	}
+
+	todos := []dto.Todo{}
+	result := db.Order(field.Column + " " + direction).Order("id " + direction).Limit(query.Limit + 1).Find(&todos)
+	if result.Error != nil {
+		return dto.TodoPage{}, result.Error
+	}
+
+	if len(todos) > query.Limit {
+		todos = todos[:query.Limit]
+		nextCursor, err := dto.EncodeTodoCursor(query.Sort, todos[len(todos)-1])
+		if err != nil {
+			return dto.TodoPage{}, err
+		}
+		page.NextCursor = nextCursor
+	}
+	page.Todos = todos
+
+	return page, nil
+}
+
+func (g *gormTodoRepositoryImpl) filter(ctx context.Context, query dto.TodoQuery) *gorm.DB {
+	db := conn(ctx, g.db).Model(&dto.Todo{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	db = whereRange(db, "created_at", query.Created)
+	db = whereRange(db, "updated_at", query.Updated)
+	db = whereRange(db, "completed_at", query.Completed)
+	if field, _ := query.SortField(); field.Present != nil {
+		db = db.Where(field.Column + " IS NOT NULL")
+	}
+	return db
+}
+
+func whereRange(db *gorm.DB, column string, r dto.TimeRange) *gorm.DB {
+	if !r.After.IsZero() {
+		db = db.Where(column+" >= ?", r.After)
+	}
+	if !r.Before.IsZero() {
+		db = db.Where(column+" < ?", r.Before)
+	}
+	return db
+}
+
+type todoSearchRow struct {
+	dto.Todo           `gorm:"embedded"`
+	Rank               float64
+	TopicSnippet       string
+	DescriptionSnippet string
+}
+
+// Search ranks todos against a websearch-style query using the search_vector column. The snippets
+// are built from HTML-escaped text, so the only markup in them is the <mark> ts_headline adds.
+func (g *gormTodoRepositoryImpl) Search(ctx context.Context, query dto.TodoSearchQuery) ([]dto.TodoSearchResult, error) {
+	var rows []todoSearchRow
+	result := conn(ctx, g.db).Raw(`
+		SELECT todos.*,
+			ts_rank(search_vector, q) AS rank,
+			ts_headline('simple', `+escapedHTML("topic")+`, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS topic_snippet,
+			ts_headline('simple', `+escapedHTML("description")+`, q, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2') AS description_snippet
+		FROM todos, websearch_to_tsquery('simple', ?) AS q
+		WHERE todos.deleted_at IS NULL AND search_vector @@ q
+		ORDER BY rank DESC, id
+		LIMIT ?`, query.Text, query.Limit).Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	results := make([]dto.TodoSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, dto.TodoSearchResult{
+			Todo:               row.Todo,
+			Rank:               row.Rank,
+			TopicSnippet:       row.TopicSnippet,
+			DescriptionSnippet: row.DescriptionSnippet,
+		})
+	}
+	return results, nil
+}
+
+// escapedHTML returns SQL that escapes column the way html.EscapeString does, reading NULL as "".
+func escapedHTML(column string) string {
+	expression := "coalesce(" + column + ", '')"
+	for _, entity := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&#34;"}, {"'", "&#39;"}} {
+		expression = fmt.Sprintf("replace(%s, '%s', '%s')", expression, strings.ReplaceAll(entity[0], "'", "''"), entity[1])
+	}
+	return expression
+}
+
+func (g *gormTodoRepositoryImpl) FindChildren(ctx context.Context, parentId string) ([]dto.Todo, error) {
+	todos := []dto.Todo{}
+	rank := dto.TodoSortFields[dto.DefaultTodoSort].Column
+	result := conn(ctx, g.db).Where("parent_id = ?", parentId).Order(rank).Order("id").Find(&todos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
+// FindSubtree walks down from rootId with a recursive CTE, so a whole subtree is one query.
+func (g *gormTodoRepositoryImpl) FindSubtree(ctx context.Context, rootId string) ([]dto.Todo, error) {
+	todos := []dto.Todo{}
+	result := conn(ctx, g.db).Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id, 1 AS depth FROM todos WHERE parent_id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT todos.id, subtree.depth + 1 FROM todos JOIN subtree ON todos.parent_id = subtree.id
+			WHERE todos.deleted_at IS NULL
+		)
+		SELECT todos.* FROM todos JOIN subtree ON todos.id = subtree.id
+		ORDER BY subtree.depth, `+dto.TodoSortFields[dto.DefaultTodoSort].Column+`, todos.id`, rootId).Scan(&todos)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return todos, nil
 }
 
-func (g *gormTodoRepositoryImpl) Save(input dto.Todo) error {
+type todoProgressRow struct {
+	RootId string
+	Done   int
+	Total  int
+}
+
+// Progress walks the subtrees below ids in one recursive CTE, carrying each row's root along.
+func (g *gormTodoRepositoryImpl) Progress(ctx context.Context, ids []string) (map[string]dto.TodoProgress, error) {
+	progress := map[string]dto.TodoProgress{}
+	if len(ids) == 0 {
+		return progress, nil
+	}
+
+	var rows []todoProgressRow
+	result := conn(ctx, g.db).Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT parent_id AS root_id, id, status FROM todos WHERE parent_id IN ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT subtree.root_id, todos.id, todos.status FROM todos JOIN subtree ON todos.parent_id = subtree.id
+			WHERE todos.deleted_at IS NULL
+		)
+		SELECT root_id, SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS done, COUNT(*) AS total
+		FROM subtree GROUP BY root_id`, ids, dto.TodoStatusDone).Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, row := range rows {
+		progress[row.RootId] = dto.NewTodoProgress(row.Done, row.Total)
+	}
+	return progress, nil
+}
+
+func (g *gormTodoRepositoryImpl) FindById(ctx context.Context, id string) (dto.Todo, error) {
+	var todo dto.Todo
+	result := conn(ctx, g.db).Where("id = ?", id).First(&todo)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return dto.Todo{}, dto.ErrTodoNotFound
+	}
+	if result.Error != nil {
+		return dto.Todo{}, result.Error
+	}
+	return todo, nil
+}
+
+// FindByIdForUpdate loads a todo, trashed or not, and locks its row until the surrounding transaction ends.
+func (g *gormTodoRepositoryImpl) FindByIdForUpdate(ctx context.Context, id string) (dto.Todo, error) {
+	var todo dto.Todo
+	result := conn(ctx, g.db).Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&todo)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return dto.Todo{}, dto.ErrTodoNotFound
+	}
+	if result.Error != nil {
+		return dto.Todo{}, result.Error
+	}
+	return todo, nil
+}
+
+func (g *gormTodoRepositoryImpl) Save(ctx context.Context, input dto.Todo) error {
 	todo := dto.Todo{
-		Id:          input.Id,
-		Topic:       input.Topic,
-		Description: input.Description,
-		Status:      input.Status,
+		Id:             input.Id,
+		ParentId:       input.ParentId,
+		Topic:          input.Topic,
+		Description:    input.Description,
+		Status:         input.Status,
+		Priority:       input.Priority,
+		Position:       input.Position,
+		Version:        input.Version,
+		CreatedAt:      input.CreatedAt,
+		UpdatedAt:      input.UpdatedAt,
+		CompletedAt:    input.CompletedAt,
+		Due:            input.Due,
+		Reminders:      input.Reminders,
+		NextReminderAt: input.NextReminder(time.Now()),
 	}
-	if result := g.db.Create(&todo); result.Error != nil {
+	if result := conn(ctx, g.db).Create(&todo); result.Error != nil {
 		return result.Error
 	}
 
 	return nil
 }
-func (g *gormTodoRepositoryImpl) Update(input dto.TodoInputUpdateStatus) error {
-	return nil
+
+func (g *gormTodoRepositoryImpl) Update(ctx context.Context, input dto.TodoInputUpdateStatus) (dto.Todo, error) {
+	var todo dto.Todo
+	query := conn(ctx, g.db).Model(&todo).Clauses(clause.Returning{}).Where("id = ?", input.Id)
+	if input.ExpectedVersion != 0 {
+		query = query.Where("version = ?", input.ExpectedVersion)
+	}
+
+	now := time.Now()
+	completedAt := interface{}(nil)
+	if input.Status == dto.TodoStatusDone {
+		completedAt = gorm.Expr("COALESCE(completed_at, ?)", now)
+	}
+	result := query.Updates(map[string]interface{}{
+		"status":       input.Status,
+		"version":      gorm.Expr("version + 1"),
+		"updated_at":   now,
+		"completed_at": completedAt,
+	})
+	if result.Error != nil {
+		return dto.Todo{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dto.Todo{}, g.missingOrConflict(ctx, input.Id)
+	}
+
+	return todo, nil
+}
+
+func (g *gormTodoRepositoryImpl) Schedule(ctx context.Context, input dto.TodoInputSchedule) (dto.Todo, error) {
+	reminders, err := json.Marshal(input.Reminders)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+
+	var todo dto.Todo
+	query := conn(ctx, g.db).Model(&todo).Clauses(clause.Returning{}).Where("id = ?", input.Id)
+	if input.ExpectedVersion != 0 {
+		query = query.Where("version = ?", input.ExpectedVersion)
+	}
+
+	now := time.Now()
+	scheduled := dto.Todo{Due: input.Due, Reminders: input.Reminders}
+	result := query.Updates(map[string]interface{}{
+		"due_at":           input.Due.At,
+		"due_all_day":      input.Due.AllDay,
+		"reminders":        string(reminders),
+		"next_reminder_at": scheduled.NextReminder(now),
+		"version":          gorm.Expr("version + 1"),
+		"updated_at":       now,
+	})
+	if result.Error != nil {
+		return dto.Todo{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dto.Todo{}, g.missingOrConflict(ctx, input.Id)
+	}
+
+	return todo, nil
 }
 
-func (g *gormTodoRepositoryImpl) Delete(input dto.TodoInputDelete) error {
+func (g *gormTodoRepositoryImpl) Move(ctx context.Context, input dto.TodoInputMove) (dto.Todo, error) {
+	var todo dto.Todo
+	query := conn(ctx, g.db).Model(&todo).Clauses(clause.Returning{}).Where("id = ?", input.Id)
+	if input.ExpectedVersion != 0 {
+		query = query.Where("version = ?", input.ExpectedVersion)
+	}
+
+	updates := map[string]interface{}{
+		"position":   input.Position,
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": time.Now(),
+	}
+	if input.Priority != nil {
+		updates["priority"] = *input.Priority
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return dto.Todo{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dto.Todo{}, g.missingOrConflict(ctx, input.Id)
+	}
+
+	return todo, nil
+}
+
+func (g *gormTodoRepositoryImpl) LastPosition(ctx context.Context) (string, error) {
+	return g.position(conn(ctx, g.db).Model(&dto.Todo{}).Order("position DESC"))
+}
+
+func (g *gormTodoRepositoryImpl) AdjacentPosition(ctx context.Context, priority dto.TodoPriority, position string, after bool) (string, error) {
+	db := conn(ctx, g.db).Model(&dto.Todo{}).Where("priority = ?", priority)
+	if after {
+		return g.position(db.Where("position > ?", position).Order("position"))
+	}
+	return g.position(db.Where("position < ?", position).Order("position DESC"))
+}
+
+// position returns the position of the first todo db orders, or "" when it finds none.
+func (g *gormTodoRepositoryImpl) position(db *gorm.DB) (string, error) {
+	var positions []string
+	if err := db.Limit(1).Pluck("position", &positions).Error; err != nil {
+		return "", err
+	}
+	if len(positions) == 0 {
+		return "", nil
+	}
+	return positions[0], nil
+}
+
+func (g *gormTodoRepositoryImpl) Delete(ctx context.Context, input dto.TodoInputDelete) (dto.Todo, error) {
+	var todo dto.Todo
+	query := conn(ctx, g.db).Model(&todo).Clauses(clause.Returning{}).Where("id = ?", input.Id)
+	if input.ExpectedVersion != 0 {
+		query = query.Where("version = ?", input.ExpectedVersion)
+	}
+
+	now := time.Now()
+	result := query.Updates(map[string]interface{}{
+		"deleted_at": now,
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": now,
+	})
+	if result.Error != nil {
+		return dto.Todo{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dto.Todo{}, g.missingOrConflict(ctx, input.Id)
+	}
+
+	return todo, nil
+}
+
+func (g *gormTodoRepositoryImpl) FindTrash(ctx context.Context) ([]dto.Todo, error) {
+	var todos []dto.Todo
+	result := conn(ctx, g.db).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&todos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
+func (g *gormTodoRepositoryImpl) Restore(ctx context.Context, id string) (dto.Todo, error) {
+	var todo dto.Todo
+	result := conn(ctx, g.db).Unscoped().Model(&todo).Clauses(clause.Returning{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return dto.Todo{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dto.Todo{}, dto.ErrTodoNotFound
+	}
+
+	return todo, nil
+}
+
+// Purge deletes a trashed todo that has no subtasks left; the parent_id foreign key backs this up.
+func (g *gormTodoRepositoryImpl) Purge(ctx context.Context, id string) error {
+	result := conn(ctx, g.db).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Where("NOT EXISTS (SELECT 1 FROM todos AS subtasks WHERE subtasks.parent_id = todos.id)").
+		Delete(&dto.Todo{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return g.missingOrParent(ctx, id)
+	}
+
 	return nil
 }
+
+// missingOrParent explains why a trashed todo could not be purged: it has subtasks, or it is not
+// in the trash at all.
+func (g *gormTodoRepositoryImpl) missingOrParent(ctx context.Context, id string) error {
+	var subtasks []string
+	err := conn(ctx, g.db).Unscoped().Model(&dto.Todo{}).
+		Where("parent_id = ?", id).
+		Where("EXISTS (SELECT 1 FROM todos AS parents WHERE parents.id = todos.parent_id AND parents.deleted_at IS NOT NULL)").
+		Order("id").Pluck("id", &subtasks).Error
+	if err != nil {
+		return err
+	}
+	if len(subtasks) > 0 {
+		return &dto.OpenSubtasksError{TodoId: id, Open: subtasks}
+	}
+	return dto.ErrTodoNotFound
+}
+
+// PurgeDeletedBefore leaves out todos with a subtask below them that is not purged in the same
+// statement, walking each subtree with a recursive CTE.
+func (g *gormTodoRepositoryImpl) PurgeDeletedBefore(ctx context.Context, before time.Time) ([]dto.Todo, error) {
+	purged := []dto.Todo{}
+	result := conn(ctx, g.db).Unscoped().Clauses(clause.Returning{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where(`id NOT IN (
+			WITH RECURSIVE subtree AS (
+				SELECT parent_id AS root_id, id, deleted_at FROM todos
+				WHERE parent_id IN (SELECT id FROM todos WHERE deleted_at IS NOT NULL AND deleted_at < ?)
+				UNION ALL
+				SELECT subtree.root_id, todos.id, todos.deleted_at FROM todos JOIN subtree ON todos.parent_id = subtree.id
+			)
+			SELECT root_id FROM subtree WHERE deleted_at IS NULL OR deleted_at >= ?
+		)`, before, before).
+		Delete(&purged)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return purged, nil
+}
+
+func (g *gormTodoRepositoryImpl) WriteNext(ctx context.Context, todo dto.Todo) (int64, error) {
+	todo.NextReminderAt = todo.NextReminder(time.Now())
+	if todo.Version == 1 {
+		result := conn(ctx, g.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&todo)
+		if result.Error != nil || result.RowsAffected > 0 {
+			return 0, result.Error
+		}
+	}
+
+	var stored int64
+	result := conn(ctx, g.db).Unscoped().Model(&dto.Todo{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", todo.Id).Select("version").Take(&stored)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if result.Error != nil || stored != todo.Version-1 {
+		return stored, result.Error
+	}
+
+	reminders, err := json.Marshal(todo.Reminders)
+	if err != nil {
+		return stored, err
+	}
+	result = conn(ctx, g.db).Unscoped().Model(&dto.Todo{}).
+		Where("id = ? AND version = ?", todo.Id, stored).
+		Updates(map[string]interface{}{
+			"topic":            todo.Topic,
+			"description":      todo.Description,
+			"status":           todo.Status,
+			"priority":         todo.Priority,
+			"position":         todo.Position,
+			"updated_at":       todo.UpdatedAt,
+			"completed_at":     todo.CompletedAt,
+			"due_at":           todo.Due.At,
+			"due_all_day":      todo.Due.AllDay,
+			"reminders":        string(reminders),
+			"next_reminder_at": todo.NextReminderAt,
+			"deleted_at":       todo.DeletedAt,
+			"version":          todo.Version,
+		})
+	return stored, result.Error
+}
+
+func (g *gormTodoRepositoryImpl) FindDue(ctx context.Context, r dto.TimeRange, limit int) ([]dto.Todo, error) {
+	todos := []dto.Todo{}
+	db := conn(ctx, g.db).Where("due_at IS NOT NULL AND status <> ?", dto.TodoStatusDone)
+	result := whereRange(db, "due_at", r).Order("due_at").Order("id").Limit(limit).Find(&todos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
+func (g *gormTodoRepositoryImpl) FindRemindersDue(ctx context.Context, now time.Time, limit int) ([]dto.Todo, error) {
+	todos := []dto.Todo{}
+	result := conn(ctx, g.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("next_reminder_at <= ? AND status <> ?", now, dto.TodoStatusDone).
+		Order("next_reminder_at").
+		Limit(limit).
+		Find(&todos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
+func (g *gormTodoRepositoryImpl) SetNextReminder(ctx context.Context, id string, next *time.Time) error {
+	return conn(ctx, g.db).Model(&dto.Todo{}).Where("id = ?", id).Update("next_reminder_at", next).Error
+}
+
+// missingOrConflict explains why a conditional write matched no rows.
+func (g *gormTodoRepositoryImpl) missingOrConflict(ctx context.Context, id string) error {
+	var count int64
+	if err := conn(ctx, g.db).Model(&dto.Todo{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return dto.ErrTodoNotFound
+	}
+	return dto.ErrVersionConflict
+}
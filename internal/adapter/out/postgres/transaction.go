@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+type gormTransactorImpl struct {
+	db *gorm.DB
+}
+
+func NewGormTransactor(db *gorm.DB) repository.Transactor {
+	return &gormTransactorImpl{db: db}
+}
+
+func (g *gormTransactorImpl) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// conn returns the transaction started by WithinTransaction for ctx, or db bound to ctx.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// Conn is conn for the repositories built on these ones in other packages, such as SQLite's.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return conn(ctx, db)
+}
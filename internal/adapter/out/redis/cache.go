@@ -2,35 +2,167 @@ package redis
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
 	"github.com/redis/go-redis/v9"
 )
 
-type Cache interface {
-	Get(ctx context.Context, key string) (string, error)
-	Set(ctx context.Context, key string, value string) error
-}
+// setIfNewerScript compares the version prefix of the stored value with the incoming one before
+// writing, so the check and the write cannot interleave with another client.
+var setIfNewerScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+	local stored = tonumber(string.match(current, '^(%d+):'))
+	if stored and stored > tonumber(ARGV[2]) then
+		return 0
+	end
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// globEscaper quotes the characters SCAN MATCH treats as glob syntax.
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
 
 type redisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-func NewRedisCache(client *redis.Client) cache.Cache {
+// NewRedisCache works on a single node, Sentinel or Cluster client. Multi-key operations are
+// pipelined per key rather than sent as MGET or DEL, which a cluster rejects across hash slots.
+func NewRedisCache(client redis.UniversalClient) cache.Cache {
 	return &redisCache{client: client}
 }
 
 func (r *redisCache) Get(ctx context.Context, key string) (string, error) {
-	return r.client.Get(ctx, key).Result()
+	value, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", cache.ErrCacheMiss
+	}
+	return value, err
+}
+
+func (r *redisCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *redisCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *redisCache) Exists(ctx context.Context, key string) (bool, error) {
+	count, err := r.client.Exists(ctx, key).Result()
+	return count > 0, err
 }
 
-func (r *redisCache) Set(ctx context.Context, key string, value string, expiration int64) error {
-	duration := time.Duration(expiration)
-	return r.client.Set(ctx, key, value, duration).Err()
+func (r *redisCache) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	values := map[string]string{}
+	if len(keys) == 0 {
+		return values, nil
+	}
+	commands := make([]*redis.StringCmd, len(keys))
+	// Missing keys fail their GET with redis.Nil, so errors are read per command instead.
+	_, _ = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for index, key := range keys {
+			commands[index] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	for index, command := range commands {
+		value, err := command.Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[keys[index]] = value
+	}
+	return values, nil
 }
 
-func (r *redisCache) Del(ctx context.Context, key string) error {
-	err := r.client.Del(ctx, key).Err()
+// MSet writes values in one round trip. MSET itself cannot take a TTL, so each key is a SET.
+func (r *redisCache) MSet(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			pipe.Set(ctx, key, value, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// Scan walks every master of a cluster, since SCAN only sees the keys of the node it runs on.
+func (r *redisCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	match := globEscaper.Replace(prefix) + "*"
+	cluster, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, r.client, match)
+	}
+
+	var mu sync.Mutex
+	keys := []string{}
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		nodeKeys, err := scanNode(ctx, node, match)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, nodeKeys...)
+		return nil
+	})
+	return keys, err
+}
+
+func scanNode(ctx context.Context, client redis.UniversalClient, match string) ([]string, error) {
+	keys := []string{}
+	iter := client.Scan(ctx, 0, match, 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+func (r *redisCache) SetIfNewer(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	versions := make(map[string]int64, len(values))
+	for key, value := range values {
+		version, err := cache.ValueVersion(value)
+		if err != nil {
+			return err
+		}
+		versions[key] = version
+	}
+
+	// EVAL rather than EVALSHA: a pipelined EVALSHA cannot fall back when the script is not loaded.
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			setIfNewerScript.Eval(ctx, pipe, []string{key}, value, versions[key], ttl.Milliseconds())
+		}
+		return nil
+	})
 	return err
 }
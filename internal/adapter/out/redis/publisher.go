@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/event"
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStreamPublisher struct {
+	client redis.UniversalClient
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamPublisher appends events to a Redis stream trimmed to roughly maxLen entries.
+// Consumers deduplicate on the event_id field.
+func NewRedisStreamPublisher(client redis.UniversalClient, stream string, maxLen int64) event.Publisher {
+	return &redisStreamPublisher{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (r *redisStreamPublisher) Publish(ctx context.Context, event dto.OutboxEvent) error {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.stream,
+		MaxLen: r.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"event_id":     event.Id,
+			"event_type":   event.EventType,
+			"aggregate_id": event.AggregateId,
+			"payload":      event.Payload,
+			"occurred_at":  event.OccurredAt.Format(time.RFC3339Nano),
+		},
+	}).Err()
+}
@@ -0,0 +1,182 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// invalidation is broadcast on the pub/sub channel after a write so other instances evict keys
+// from their L1. Origin lets an instance skip its own messages.
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+type tieredCache struct {
+	l1      cache.Cache
+	l2      cache.Cache
+	client  redis.UniversalClient
+	channel string
+	origin  string
+}
+
+// NewTieredCache serves reads from l1 before falling back to l2, and publishes every write on
+// channel so all instances drop the keys from their l1. Pub/sub delivery is best effort, so an
+// instance that misses a message serves the old value until its l1 entry expires.
+func NewTieredCache(ctx context.Context, l1 cache.Cache, l2 cache.Cache, client redis.UniversalClient, channel string) cache.Cache {
+	t := &tieredCache{
+		l1:      l1,
+		l2:      l2,
+		client:  client,
+		channel: channel,
+		origin:  uuid.NewString(),
+	}
+	go t.listen(ctx)
+	return t
+}
+
+func (t *tieredCache) Get(ctx context.Context, key string) (string, error) {
+	if value, err := t.l1.Get(ctx, key); err == nil {
+		return value, nil
+	}
+	value, err := t.l2.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return value, t.l1.Set(ctx, key, value, 0)
+}
+
+func (t *tieredCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if err := t.l2.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	if err := t.l1.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return t.publish(ctx, key)
+}
+
+func (t *tieredCache) Del(ctx context.Context, keys ...string) error {
+	if err := t.l2.Del(ctx, keys...); err != nil {
+		return err
+	}
+	if err := t.l1.Del(ctx, keys...); err != nil {
+		return err
+	}
+	return t.publish(ctx, keys...)
+}
+
+func (t *tieredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if exists, err := t.l1.Exists(ctx, key); err == nil && exists {
+		return true, nil
+	}
+	return t.l2.Exists(ctx, key)
+}
+
+func (t *tieredCache) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	values, err := t.l1.MGet(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	missing := make([]string, 0, len(keys)-len(values))
+	for _, key := range keys {
+		if _, ok := values[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	found, err := t.l2.MGet(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range found {
+		values[key] = value
+	}
+	return values, t.l1.MSet(ctx, found, 0)
+}
+
+func (t *tieredCache) MSet(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if err := t.l2.MSet(ctx, values, ttl); err != nil {
+		return err
+	}
+	if err := t.l1.MSet(ctx, values, ttl); err != nil {
+		return err
+	}
+	return t.publish(ctx, keysOf(values)...)
+}
+
+// Scan lists the shared keys; l1 only ever holds a subset of them.
+func (t *tieredCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	return t.l2.Scan(ctx, prefix)
+}
+
+// SetIfNewer only evicts the local copies: whether l2 kept each value is decided by the script,
+// so the next read fetches whichever version won.
+func (t *tieredCache) SetIfNewer(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if err := t.l2.SetIfNewer(ctx, values, ttl); err != nil {
+		return err
+	}
+	keys := keysOf(values)
+	if err := t.l1.Del(ctx, keys...); err != nil {
+		return err
+	}
+	return t.publish(ctx, keys...)
+}
+
+func (t *tieredCache) publish(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(invalidation{Origin: t.origin, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return t.client.Publish(ctx, t.channel, data).Err()
+}
+
+// listen evicts keys written by other instances until ctx is done.
+func (t *tieredCache) listen(ctx context.Context) {
+	pubsub := t.client.Subscribe(ctx, t.channel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event invalidation
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				logger.Log.Warn("Ignoring malformed cache invalidation.", zap.Error(err))
+				continue
+			}
+			if event.Origin == t.origin {
+				continue
+			}
+			if err := t.l1.Del(ctx, event.Keys...); err != nil {
+				logger.Log.Warn("Error evicting invalidated cache keys.", zap.Error(err))
+			}
+		}
+	}
+}
+
+func keysOf(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	return keys
+}
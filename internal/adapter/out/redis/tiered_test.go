@@ -0,0 +1,116 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/memory"
+	adapter "github.com/VanillaSkys/todo_fiber/internal/adapter/out/redis"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const channel = "todo_fiber:cache:invalidate"
+
+func TestTieredCacheInvalidatesOtherInstances(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	testCases := []struct {
+		description string
+		write       func(ctx context.Context, writer cache.Cache) error
+		expected    string
+		expectedErr error
+	}{
+		{
+			description: "Set on one instance evicts the key from the others.",
+			write: func(ctx context.Context, writer cache.Cache) error {
+				return writer.Set(ctx, "item:1", "2:new", 0)
+			},
+			expected: "2:new",
+		},
+		{
+			description: "MSet on one instance evicts the keys from the others.",
+			write: func(ctx context.Context, writer cache.Cache) error {
+				return writer.MSet(ctx, map[string]string{"item:1": "2:new"}, 0)
+			},
+			expected: "2:new",
+		},
+		{
+			description: "SetIfNewer on one instance evicts the keys from the others.",
+			write: func(ctx context.Context, writer cache.Cache) error {
+				return writer.SetIfNewer(ctx, map[string]string{"item:1": "2:new"}, 0)
+			},
+			expected: "2:new",
+		},
+		{
+			description: "Del on one instance evicts the keys from the others.",
+			write: func(ctx context.Context, writer cache.Cache) error {
+				return writer.Del(ctx, "item:1")
+			},
+			expectedErr: cache.ErrCacheMiss,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			server := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			defer client.Close()
+
+			// Both instances share l2 and keep the key in their own l1 for longer than the test runs.
+			l2 := adapter.NewRedisCache(client)
+			reader := adapter.NewTieredCache(ctx, memory.NewLRUCache(10, time.Hour), l2, client, channel)
+			writer := adapter.NewTieredCache(ctx, memory.NewLRUCache(10, time.Hour), l2, client, channel)
+			assert.Eventually(t, func() bool {
+				return server.PubSubNumSub(channel)[channel] == 2
+			}, time.Second, 10*time.Millisecond)
+
+			assert.NoError(t, l2.Set(ctx, "item:1", "1:old", 0))
+			value, err := reader.Get(ctx, "item:1")
+			assert.NoError(t, err)
+			assert.Equal(t, "1:old", value)
+
+			// Act
+			assert.NoError(t, testCase.write(ctx, writer))
+
+			// Assert
+			assert.Eventually(t, func() bool {
+				value, err := reader.Get(ctx, "item:1")
+				return err == testCase.expectedErr && value == testCase.expected
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestTieredCacheIgnoresOwnInvalidations(t *testing.T) {
+	// Arrange
+	logger.Log = zap.NewNop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	l1 := memory.NewLRUCache(10, time.Hour)
+	tiered := adapter.NewTieredCache(ctx, l1, adapter.NewRedisCache(client), client, channel)
+	assert.Eventually(t, func() bool {
+		return server.PubSubNumSub(channel)[channel] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Act
+	assert.NoError(t, tiered.Set(ctx, "item:1", "1:value", 0))
+
+	// Assert
+	time.Sleep(50 * time.Millisecond)
+	value, err := l1.Get(ctx, "item:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1:value", value)
+}
@@ -0,0 +1,170 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+type redisWriteBuffer struct {
+	client         redis.UniversalClient
+	stream         string
+	group          string
+	consumer       string
+	claimTimeout   time.Duration
+	minReplicas    int
+	replicaTimeout time.Duration
+	grouped        atomic.Bool
+}
+
+// NewRedisWriteBuffer keeps buffered writes in a stream read through the consumer group group, so
+// every instance flushes a share and writes claimed by an instance that died are taken over after
+// claimTimeout. With minReplicas above zero an append only succeeds once that many replicas have
+// the write, as reported by WAIT; durability across restarts also needs appendonly enabled.
+func NewRedisWriteBuffer(client redis.UniversalClient, stream string, group string, consumer string, claimTimeout time.Duration, minReplicas int, replicaTimeout time.Duration) cache.WriteBuffer {
+	return &redisWriteBuffer{
+		client:         client,
+		stream:         stream,
+		group:          group,
+		consumer:       consumer,
+		claimTimeout:   claimTimeout,
+		minReplicas:    minReplicas,
+		replicaTimeout: replicaTimeout,
+	}
+}
+
+func (r *redisWriteBuffer) Append(ctx context.Context, write dto.BufferedWrite) error {
+	data, err := json.Marshal(write)
+	if err != nil {
+		return err
+	}
+
+	// WAIT counts the replicas that have every write made on its connection, so it is pipelined
+	// with the XADD to share one.
+	var replicas *redis.Cmd
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: r.stream, Values: map[string]interface{}{"write": data}})
+		if r.minReplicas > 0 {
+			replicas = pipe.Do(ctx, "WAIT", r.minReplicas, r.replicaTimeout.Milliseconds())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if replicas == nil {
+		return nil
+	}
+	acknowledged, err := replicas.Int64()
+	if err != nil {
+		return err
+	}
+	if acknowledged < int64(r.minReplicas) {
+		return fmt.Errorf("buffered write reached %d of %d replicas", acknowledged, r.minReplicas)
+	}
+	return nil
+}
+
+func (r *redisWriteBuffer) Claim(ctx context.Context, limit int) ([]dto.BufferedWrite, error) {
+	if err := r.ensureGroup(ctx); err != nil {
+		return nil, err
+	}
+
+	messages, _, err := r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   r.stream,
+		Group:    r.group,
+		Consumer: r.consumer,
+		MinIdle:  r.claimTimeout,
+		Start:    "0-0",
+		Count:    int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(messages) < limit {
+		streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    r.group,
+			Consumer: r.consumer,
+			Streams:  []string{r.stream, ">"},
+			Count:    int64(limit - len(messages)),
+			Block:    -1,
+		}).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+		for _, stream := range streams {
+			messages = append(messages, stream.Messages...)
+		}
+	}
+
+	writes := make([]dto.BufferedWrite, 0, len(messages))
+	for _, message := range messages {
+		write, err := decodeBufferedWrite(message)
+		if err != nil {
+			// A write that cannot be decoded would be claimed again forever, so it is logged in
+			// full for an operator and dropped.
+			logger.Log.Error("Dropping undecodable buffered write.",
+				zap.String("id", message.ID),
+				zap.Any("values", message.Values),
+				zap.Error(err),
+			)
+			if err := r.Ack(ctx, message.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		writes = append(writes, write)
+	}
+	return writes, nil
+}
+
+// Ack removes flushed writes from the stream as well as the group, so it only holds pending ones.
+func (r *redisWriteBuffer) Ack(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, r.stream, r.group, ids...)
+		pipe.XDel(ctx, r.stream, ids...)
+		return nil
+	})
+	return err
+}
+
+// ensureGroup creates the stream and consumer group on first use. Redis may be down when the
+// buffer is built, so this is retried on every claim until it succeeds.
+func (r *redisWriteBuffer) ensureGroup(ctx context.Context) error {
+	if r.grouped.Load() {
+		return nil
+	}
+	err := r.client.XGroupCreateMkStream(ctx, r.stream, r.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	r.grouped.Store(true)
+	return nil
+}
+
+func decodeBufferedWrite(message redis.XMessage) (dto.BufferedWrite, error) {
+	data, ok := message.Values["write"].(string)
+	if !ok {
+		return dto.BufferedWrite{}, fmt.Errorf("buffered write %s has no write field", message.ID)
+	}
+	var write dto.BufferedWrite
+	if err := json.Unmarshal([]byte(data), &write); err != nil {
+		return dto.BufferedWrite{}, err
+	}
+	write.Id = message.ID
+	return write, nil
+}
@@ -0,0 +1,29 @@
+package serialization
+
+import (
+	"fmt"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+)
+
+const (
+	CodecJSON     = "json"
+	CodecMsgpack  = "msgpack"
+	CodecProtobuf = "protobuf"
+)
+
+// NewCodec returns the codec called name, compressed with algorithm above threshold bytes.
+func NewCodec(name string, algorithm string, threshold int) (codec.Codec, error) {
+	var inner codec.Codec
+	switch name {
+	case "", CodecJSON:
+		inner = NewJSONCodec()
+	case CodecMsgpack:
+		inner = NewMsgpackCodec()
+	case CodecProtobuf:
+		inner = NewProtobufCodec()
+	default:
+		return nil, fmt.Errorf("unknown cache codec %q", name)
+	}
+	return NewCompressedCodec(inner, algorithm, threshold)
+}
@@ -0,0 +1,202 @@
+package serialization_test
+
+import (
+	"database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/serialization"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	total := int64(42)
+	completedAt := time.Unix(1700000300, 5)
+	dueAt := time.Date(2023, time.November, 16, 0, 0, 0, 0, time.Local)
+	parentId := "7c1f4a52-2f0e-4f6e-9a57-1d2b7a0c9e31"
+	todo := dto.Todo{
+		Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
+		ParentId:    &parentId,
+		Topic:       "Complete Project",
+		Description: strings.Repeat("Description for Complete Project. ", 64),
+		Status:      "done",
+		Priority:    dto.TodoPriorityHigh,
+		Position:    "i0r",
+		Version:     3,
+		CreatedAt:   time.Unix(1699990000, 0),
+		UpdatedAt:   time.Unix(1700000300, 5),
+		CompletedAt: &completedAt,
+		Due:         dto.DueDate{At: &dueAt, AllDay: true},
+		Reminders:   dto.ReminderOffsets{24 * time.Hour, 30 * time.Minute},
+		DeletedAt:   gorm.DeletedAt(sql.NullTime{Time: time.Unix(1700000000, 0), Valid: true}),
+	}
+	page := dto.CachedTodoPage{
+		Ids:        []string{"a", "b"},
+		NextCursor: "eyJzIjoiaWQifQ",
+		Total:      &total,
+		FreshUntil: time.Unix(1700000000, 0),
+	}
+
+	testCases := []struct {
+		codec       string
+		compression string
+	}{
+		{codec: serialization.CodecJSON, compression: serialization.CompressionNone},
+		{codec: serialization.CodecMsgpack, compression: serialization.CompressionNone},
+		{codec: serialization.CodecProtobuf, compression: serialization.CompressionNone},
+		{codec: serialization.CodecJSON, compression: serialization.CompressionZstd},
+		{codec: serialization.CodecProtobuf, compression: serialization.CompressionSnappy},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.codec+"+"+testCase.compression, func(t *testing.T) {
+			// Arrange
+			codec, err := serialization.NewCodec(testCase.codec, testCase.compression, 256)
+			assert.NoError(t, err)
+
+			// Act
+			todoData, todoErr := codec.Marshal(todo)
+			pageData, pageErr := codec.Marshal(page)
+			var decodedTodo dto.Todo
+			var decodedPage dto.CachedTodoPage
+
+			// Assert
+			assert.NoError(t, todoErr)
+			assert.NoError(t, pageErr)
+			assert.NoError(t, codec.Unmarshal(todoData, &decodedTodo))
+			assert.NoError(t, codec.Unmarshal(pageData, &decodedPage))
+			assert.Equal(t, todo.Id, decodedTodo.Id)
+			assert.Equal(t, todo.Description, decodedTodo.Description)
+			assert.Equal(t, todo.Version, decodedTodo.Version)
+			assert.True(t, todo.CreatedAt.Equal(decodedTodo.CreatedAt))
+			assert.True(t, todo.UpdatedAt.Equal(decodedTodo.UpdatedAt))
+			assert.True(t, todo.CompletedAt.Equal(*decodedTodo.CompletedAt))
+			assert.True(t, todo.DeletedAt.Time.Equal(decodedTodo.DeletedAt.Time))
+			assert.True(t, todo.Due.At.Equal(*decodedTodo.Due.At))
+			assert.Equal(t, todo.Due.AllDay, decodedTodo.Due.AllDay)
+			assert.Equal(t, todo.Reminders, decodedTodo.Reminders)
+			assert.Equal(t, todo.Priority, decodedTodo.Priority)
+			assert.Equal(t, todo.Position, decodedTodo.Position)
+			assert.Equal(t, *todo.ParentId, *decodedTodo.ParentId)
+			assert.Equal(t, page.Ids, decodedPage.Ids)
+			assert.Equal(t, *page.Total, *decodedPage.Total)
+			assert.True(t, page.FreshUntil.Equal(decodedPage.FreshUntil))
+		})
+	}
+}
+
+func TestNewCodecRejectsUnknownNames(t *testing.T) {
+	_, err := serialization.NewCodec("gob", serialization.CompressionNone, 0)
+	assert.Error(t, err)
+
+	_, err = serialization.NewCodec(serialization.CodecJSON, "lz4", 0)
+	assert.Error(t, err)
+}
+
+// TestCodecsKeepEveryField fills every cached field by reflection, so a field added to a cached type
+// fails here until each codec carries it. Fields tagged msgpack:"-" are not cached.
+func TestCodecsKeepEveryField(t *testing.T) {
+	for _, name := range []string{serialization.CodecJSON, serialization.CodecMsgpack, serialization.CodecProtobuf} {
+		t.Run(name, func(t *testing.T) {
+			codec, err := serialization.NewCodec(name, serialization.CompressionNone, 0)
+			assert.NoError(t, err)
+
+			for _, value := range []any{&dto.Todo{}, &dto.CachedTodoPage{}} {
+				// Arrange
+				seed := 0
+				filled := reflect.ValueOf(value).Elem()
+				fill(t, filled, &seed)
+
+				// Act
+				data, err := codec.Marshal(filled.Interface())
+				assert.NoError(t, err)
+				decoded := reflect.New(filled.Type())
+				assert.NoError(t, codec.Unmarshal(data, decoded.Interface()))
+
+				// Assert
+				assertSameFields(t, filled.Type().Name(), filled, decoded.Elem())
+			}
+		})
+	}
+}
+
+// fillers set values whose type only allows some contents, such as an all-day due date, which must
+// hold the midnight that ends its day.
+var fillers = map[reflect.Type]func(seed int) any{
+	reflect.TypeOf(time.Time{}): func(seed int) any {
+		return time.Unix(1700000000+int64(seed), int64(seed)).In(time.FixedZone("UTC+7", 7*60*60))
+	},
+	reflect.TypeOf(dto.TodoPriority(0)): func(seed int) any {
+		return dto.TodoPriority(1 + seed%int(dto.TodoPriorityUrgent))
+	},
+	reflect.TypeOf(dto.DueDate{}): func(seed int) any {
+		at := time.Date(2024, time.May, 1+seed, 0, 0, 0, 0, time.Local)
+		return dto.DueDate{At: &at, AllDay: true}
+	},
+}
+
+// fill sets every cached field below value to a non-zero value distinct from the others.
+func fill(t *testing.T, value reflect.Value, seed *int) {
+	*seed++
+	if filler, ok := fillers[value.Type()]; ok {
+		value.Set(reflect.ValueOf(filler(*seed)))
+		return
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(value.Type().Name() + strconv.Itoa(*seed))
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		value.SetInt(int64(*seed))
+	case reflect.Bool:
+		value.SetBool(true)
+	case reflect.Pointer:
+		value.Set(reflect.New(value.Type().Elem()))
+		fill(t, value.Elem(), seed)
+	case reflect.Slice:
+		value.Set(reflect.MakeSlice(value.Type(), 2, 2))
+		for index := range value.Len() {
+			fill(t, value.Index(index), seed)
+		}
+	case reflect.Struct:
+		for index := range value.NumField() {
+			if field := value.Type().Field(index); field.IsExported() && field.Tag.Get("msgpack") != "-" {
+				fill(t, value.Field(index), seed)
+			}
+		}
+	default:
+		t.Fatalf("no filler for %s", value.Type())
+	}
+}
+
+func assertSameFields(t *testing.T, path string, expected reflect.Value, actual reflect.Value) {
+	t.Helper()
+	if expected, ok := expected.Interface().(time.Time); ok {
+		assert.True(t, expected.Equal(actual.Interface().(time.Time)), "%s: %v, got %v", path, expected, actual)
+		return
+	}
+	switch expected.Kind() {
+	case reflect.Pointer:
+		if assert.False(t, actual.IsNil(), "%s is missing", path) {
+			assertSameFields(t, path, expected.Elem(), actual.Elem())
+		}
+	case reflect.Slice:
+		if assert.Equal(t, expected.Len(), actual.Len(), "%s length", path) {
+			for index := range expected.Len() {
+				assertSameFields(t, path+"["+strconv.Itoa(index)+"]", expected.Index(index), actual.Index(index))
+			}
+		}
+	case reflect.Struct:
+		for index := range expected.NumField() {
+			if field := expected.Type().Field(index); field.IsExported() && field.Tag.Get("msgpack") != "-" {
+				assertSameFields(t, path+"."+field.Name, expected.Field(index), actual.Field(index))
+			}
+		}
+	default:
+		assert.Equal(t, expected.Interface(), actual.Interface(), path)
+	}
+}
@@ -0,0 +1,99 @@
+package serialization
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CompressionNone   = "none"
+	CompressionZstd   = "zstd"
+	CompressionSnappy = "snappy"
+)
+
+// The first byte of every compressed codec value says how the rest is stored, so changing the
+// threshold keeps existing values readable.
+const (
+	flagRaw byte = iota
+	flagZstd
+	flagSnappy
+)
+
+// maxDecodedSize bounds the memory a corrupted or hostile zstd frame can claim.
+const maxDecodedSize = 64 << 20
+
+var errCorruptValue = errors.New("corrupt compressed cache value")
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecodedSize))
+)
+
+type compressedCodec struct {
+	codec     codec.Codec
+	algorithm string
+	threshold int
+}
+
+// NewCompressedCodec compresses values of inner that encode to at least threshold bytes. With
+// CompressionNone it returns inner unchanged.
+func NewCompressedCodec(inner codec.Codec, algorithm string, threshold int) (codec.Codec, error) {
+	switch algorithm {
+	case "", CompressionNone:
+		return inner, nil
+	case CompressionZstd, CompressionSnappy:
+		return &compressedCodec{codec: inner, algorithm: algorithm, threshold: threshold}, nil
+	}
+	return nil, fmt.Errorf("unknown cache compression %q", algorithm)
+}
+
+func (c *compressedCodec) Name() string {
+	return c.codec.Name() + "+" + c.algorithm
+}
+
+func (c *compressedCodec) Marshal(v any) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < c.threshold {
+		return append([]byte{flagRaw}, data...), nil
+	}
+	if c.algorithm == CompressionZstd {
+		return zstdEncoder.EncodeAll(data, []byte{flagZstd}), nil
+	}
+	return append([]byte{flagSnappy}, snappy.Encode(nil, data)...), nil
+}
+
+func (c *compressedCodec) Unmarshal(data []byte, v any) error {
+	if len(data) == 0 {
+		return errCorruptValue
+	}
+
+	payload := data[1:]
+	switch data[0] {
+	case flagRaw:
+	case flagZstd:
+		decoded, err := zstdDecoder.DecodeAll(payload, nil)
+		if err != nil {
+			return err
+		}
+		payload = decoded
+	case flagSnappy:
+		if length, err := snappy.DecodedLen(payload); err != nil || length > maxDecodedSize {
+			return errCorruptValue
+		}
+		decoded, err := snappy.Decode(nil, payload)
+		if err != nil {
+			return err
+		}
+		payload = decoded
+	default:
+		return errCorruptValue
+	}
+	return c.codec.Unmarshal(payload, v)
+}
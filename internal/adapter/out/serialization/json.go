@@ -0,0 +1,25 @@
+package serialization
+
+import (
+	"encoding/json"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+)
+
+type jsonCodec struct{}
+
+func NewJSONCodec() codec.Codec {
+	return jsonCodec{}
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,26 @@
+package serialization
+
+import (
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+	"github.com/shamaton/msgpack/v2"
+)
+
+type msgpackCodec struct{}
+
+// NewMsgpackCodec encodes structs as MessagePack maps keyed by field name, so adding a field
+// keeps older values readable.
+func NewMsgpackCodec() codec.Codec {
+	return msgpackCodec{}
+}
+
+func (msgpackCodec) Name() string {
+	return "msgpack"
+}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
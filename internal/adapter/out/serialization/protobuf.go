@@ -0,0 +1,269 @@
+package serialization
+
+import (
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type protobufCodec struct{}
+
+// NewProtobufCodec encodes the cached todo types with the messages in todo_cache.proto. Unknown
+// fields are skipped on decode, so values written by a newer build stay readable.
+func NewProtobufCodec() codec.Codec {
+	return protobufCodec{}
+}
+
+func (protobufCodec) Name() string {
+	return "protobuf"
+}
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case dto.Todo:
+		return appendTodo(nil, v), nil
+	case *dto.Todo:
+		return appendTodo(nil, *v), nil
+	case dto.CachedTodoPage:
+		return appendCachedTodoPage(nil, v), nil
+	case *dto.CachedTodoPage:
+		return appendCachedTodoPage(nil, *v), nil
+	}
+	return nil, codec.ErrUnsupportedType
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	switch v := v.(type) {
+	case *dto.Todo:
+		*v = dto.Todo{}
+		return consumeTodo(data, v)
+	case *dto.CachedTodoPage:
+		*v = dto.CachedTodoPage{}
+		return consumeCachedTodoPage(data, v)
+	}
+	return codec.ErrUnsupportedType
+}
+
+func appendTodo(b []byte, todo dto.Todo) []byte {
+	b = appendString(b, 1, todo.Id)
+	b = appendString(b, 2, todo.Topic)
+	b = appendString(b, 3, todo.Description)
+	b = appendString(b, 4, string(todo.Status))
+	b = appendInt64(b, 5, todo.Version)
+	if todo.DeletedAt.Valid {
+		b = protowire.AppendTag(b, 6, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(todo.DeletedAt.Time.UnixNano()))
+	}
+	if !todo.CreatedAt.IsZero() {
+		b = appendInt64(b, 7, todo.CreatedAt.UnixNano())
+	}
+	if !todo.UpdatedAt.IsZero() {
+		b = appendInt64(b, 8, todo.UpdatedAt.UnixNano())
+	}
+	if todo.CompletedAt != nil {
+		b = protowire.AppendTag(b, 9, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(todo.CompletedAt.UnixNano()))
+	}
+	if todo.Due.At != nil {
+		b = protowire.AppendTag(b, 10, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(todo.Due.At.UnixNano()))
+	}
+	if todo.Due.AllDay {
+		b = protowire.AppendTag(b, 11, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(true))
+	}
+	if len(todo.Reminders) > 0 {
+		var packed []byte
+		for _, offset := range todo.Reminders {
+			packed = protowire.AppendVarint(packed, uint64(offset))
+		}
+		b = protowire.AppendTag(b, 12, protowire.BytesType)
+		b = protowire.AppendBytes(b, packed)
+	}
+	b = appendInt64(b, 13, int64(todo.Priority))
+	b = appendString(b, 14, todo.Position)
+	if todo.ParentId != nil {
+		b = protowire.AppendTag(b, 15, protowire.BytesType)
+		b = protowire.AppendString(b, *todo.ParentId)
+	}
+	return b
+}
+
+func consumeTodo(data []byte, todo *dto.Todo) error {
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, data []byte) int {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			return consumeString(data, &todo.Id)
+		case num == 2 && typ == protowire.BytesType:
+			return consumeString(data, &todo.Topic)
+		case num == 3 && typ == protowire.BytesType:
+			return consumeString(data, &todo.Description)
+		case num == 4 && typ == protowire.BytesType:
+			var status string
+			n := consumeString(data, &status)
+			todo.Status = dto.TodoStatus(status)
+			return n
+		case num == 5 && typ == protowire.VarintType:
+			return consumeInt64(data, &todo.Version)
+		case num == 6 && typ == protowire.VarintType:
+			var deletedAt int64
+			n := consumeInt64(data, &deletedAt)
+			todo.DeletedAt.Time, todo.DeletedAt.Valid = time.Unix(0, deletedAt), true
+			return n
+		case num == 7 && typ == protowire.VarintType:
+			var createdAt int64
+			n := consumeInt64(data, &createdAt)
+			todo.CreatedAt = time.Unix(0, createdAt)
+			return n
+		case num == 8 && typ == protowire.VarintType:
+			var updatedAt int64
+			n := consumeInt64(data, &updatedAt)
+			todo.UpdatedAt = time.Unix(0, updatedAt)
+			return n
+		case num == 9 && typ == protowire.VarintType:
+			var completedAt int64
+			n := consumeInt64(data, &completedAt)
+			completed := time.Unix(0, completedAt)
+			todo.CompletedAt = &completed
+			return n
+		case num == 10 && typ == protowire.VarintType:
+			var dueAt int64
+			n := consumeInt64(data, &dueAt)
+			due := time.Unix(0, dueAt)
+			todo.Due.At = &due
+			return n
+		case num == 11 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			todo.Due.AllDay = protowire.DecodeBool(v)
+			return n
+		case num == 12 && typ == protowire.VarintType:
+			var offset int64
+			n := consumeInt64(data, &offset)
+			todo.Reminders = append(todo.Reminders, time.Duration(offset))
+			return n
+		case num == 12 && typ == protowire.BytesType:
+			// Repeated scalars are packed by default in proto3, but parsers must accept both forms.
+			packed, n := protowire.ConsumeBytes(data)
+			for len(packed) > 0 && n >= 0 {
+				var offset int64
+				m := consumeInt64(packed, &offset)
+				if m < 0 {
+					return m
+				}
+				todo.Reminders = append(todo.Reminders, time.Duration(offset))
+				packed = packed[m:]
+			}
+			return n
+		case num == 13 && typ == protowire.VarintType:
+			var priority int64
+			n := consumeInt64(data, &priority)
+			todo.Priority = dto.TodoPriority(priority)
+			return n
+		case num == 14 && typ == protowire.BytesType:
+			return consumeString(data, &todo.Position)
+		case num == 15 && typ == protowire.BytesType:
+			var parentId string
+			n := consumeString(data, &parentId)
+			todo.ParentId = &parentId
+			return n
+		}
+		return protowire.ConsumeFieldValue(num, typ, data)
+	})
+}
+
+func appendCachedTodoPage(b []byte, page dto.CachedTodoPage) []byte {
+	for _, id := range page.Ids {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, id)
+	}
+	b = appendString(b, 2, page.NextCursor)
+	if page.Total != nil {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(*page.Total))
+	}
+	if !page.FreshUntil.IsZero() {
+		b = appendInt64(b, 4, page.FreshUntil.UnixNano())
+	}
+	return b
+}
+
+func consumeCachedTodoPage(data []byte, page *dto.CachedTodoPage) error {
+	page.Ids = []string{}
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, data []byte) int {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			var id string
+			n := consumeString(data, &id)
+			page.Ids = append(page.Ids, id)
+			return n
+		case num == 2 && typ == protowire.BytesType:
+			return consumeString(data, &page.NextCursor)
+		case num == 3 && typ == protowire.VarintType:
+			var total int64
+			n := consumeInt64(data, &total)
+			page.Total = &total
+			return n
+		case num == 4 && typ == protowire.VarintType:
+			var freshUntil int64
+			n := consumeInt64(data, &freshUntil)
+			page.FreshUntil = time.Unix(0, freshUntil)
+			return n
+		}
+		return protowire.ConsumeFieldValue(num, typ, data)
+	})
+}
+
+// consumeFields calls field for every field in data; field returns how many bytes of the value it
+// consumed, or a negative protowire error code.
+func consumeFields(data []byte, field func(num protowire.Number, typ protowire.Type, data []byte) int) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		n = field(num, typ, data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// appendString writes a proto3 string field, leaving out the empty default.
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// appendInt64 writes a proto3 int64 field, leaving out the zero default.
+func appendInt64(b []byte, num protowire.Number, value int64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(value))
+}
+
+func consumeString(data []byte, value *string) int {
+	v, n := protowire.ConsumeString(data)
+	if n >= 0 {
+		*value = v
+	}
+	return n
+}
+
+func consumeInt64(data []byte, value *int64) int {
+	v, n := protowire.ConsumeVarint(data)
+	if n >= 0 {
+		*value = int64(v)
+	}
+	return n
+}
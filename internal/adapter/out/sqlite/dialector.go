@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	driver "github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// Open opens the SQLite database at dsn with every time it is given converted to UTC. SQLite keeps
+// times as text with the offset they were written with and compares them as text, so times in
+// other zones would not order or match ranges correctly.
+func Open(dsn string) gorm.Dialector {
+	return utcDialector{Dialector: driver.Dialector{DSN: dsn}}
+}
+
+type utcDialector struct {
+	driver.Dialector
+}
+
+func (d utcDialector) Initialize(db *gorm.DB) error {
+	if err := d.Dialector.Initialize(db); err != nil {
+		return err
+	}
+	db.ConnPool = utcConnPool{ConnPool: db.ConnPool}
+	return nil
+}
+
+// utcConnPool converts the time arguments of every statement to UTC, inside transactions too.
+type utcConnPool struct {
+	gorm.ConnPool
+}
+
+func (p utcConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, query, utc(args)...)
+}
+
+func (p utcConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, query, utc(args)...)
+}
+
+func (p utcConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, query, utc(args)...)
+}
+
+func (p utcConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	beginner, ok := p.ConnPool.(gorm.TxBeginner)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+	tx, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &utcTx{utcConnPool: utcConnPool{ConnPool: tx}, tx: tx}, nil
+}
+
+func (p utcConnPool) GetDBConn() (*sql.DB, error) {
+	if db, ok := p.ConnPool.(*sql.DB); ok {
+		return db, nil
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+type utcTx struct {
+	utcConnPool
+	tx *sql.Tx
+}
+
+func (t *utcTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *utcTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// utc returns args with every time, direct or behind a pointer or nullable wrapper, in UTC.
+func utc(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for index, arg := range args {
+		converted[index] = arg
+		switch value := arg.(type) {
+		case time.Time:
+			converted[index] = value.UTC()
+		case *time.Time:
+			if value != nil {
+				converted[index] = value.UTC()
+			}
+		case gorm.DeletedAt:
+			value.Time = value.Time.UTC()
+			converted[index] = value
+		case sql.NullTime:
+			value.Time = value.Time.UTC()
+			converted[index] = value
+		}
+	}
+	return converted
+}
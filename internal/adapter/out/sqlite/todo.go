@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/postgres"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"gorm.io/gorm"
+)
+
+// sqliteTodoRepositoryImpl reuses the GORM repository and only replaces queries that depend on
+// Postgres features.
+type sqliteTodoRepositoryImpl struct {
+	repository.TodoRepository
+	db *gorm.DB
+}
+
+func NewSQLiteTodoRepository(db *gorm.DB) repository.TodoRepository {
+	return &sqliteTodoRepositoryImpl{
+		TodoRepository: postgres.NewGormTodoRepository(db),
+		db:             db,
+	}
+}
+
+// Search narrows candidates with LIKE and ranks them in Go, since SQLite has no tsvector.
+func (s *sqliteTodoRepositoryImpl) Search(ctx context.Context, query dto.TodoSearchQuery) ([]dto.TodoSearchResult, error) {
+	db := postgres.Conn(ctx, s.db).Model(&dto.Todo{})
+	for _, term := range query.Terms() {
+		pattern := "%" + term + "%"
+		db = db.Where("(LOWER(topic) LIKE ? OR LOWER(description) LIKE ?)", pattern, pattern)
+	}
+
+	var todos []dto.Todo
+	if err := db.Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	results := []dto.TodoSearchResult{}
+	for _, todo := range todos {
+		if result, ok := query.Match(todo); ok {
+			results = append(results, result)
+		}
+	}
+
+	slices.SortFunc(results, func(a, b dto.TodoSearchResult) int {
+		if order := cmp.Compare(b.Rank, a.Rank); order != 0 {
+			return order
+		}
+		return cmp.Compare(a.Todo.Id, b.Todo.Id)
+	})
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
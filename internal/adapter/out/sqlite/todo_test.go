@@ -0,0 +1,30 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/postgres"
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/sqlite"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository/repositorytest"
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure/migration"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSQLiteTodoRepository(t *testing.T) {
+	repositorytest.TestTodoRepository(t, func(t *testing.T) (repository.TodoRepository, repository.Transactor) {
+		// Opened the way InitSQLite opens it, on a database of its own.
+		path := filepath.Join(t.TempDir(), "todo.db")
+		db, err := gorm.Open(sqlite.Open(path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"), &gorm.Config{Logger: logger.Discard})
+		assert.NoError(t, err)
+		migrator, err := migration.NewMigrator(db)
+		assert.NoError(t, err)
+		assert.NoError(t, migrator.Up(context.Background()))
+
+		return sqlite.NewSQLiteTodoRepository(db), postgres.NewGormTransactor(db)
+	})
+}
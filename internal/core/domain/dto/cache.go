@@ -0,0 +1,19 @@
+package dto
+
+// CacheReport lists where the cached todos disagree with the repository. Pending lists entries
+// ahead of the repository that may be write-behind writes waiting to be flushed.
+type CacheReport struct {
+	Checked  int      `json:"checked"`
+	Cached   int      `json:"cached"`
+	Missing  []string `json:"missing"`
+	Stale    []string `json:"stale"`
+	Extra    []string `json:"extra"`
+	Pending  []string `json:"pending"`
+	Repaired bool     `json:"repaired"`
+}
+
+// Consistent reports whether every cached todo matches the repository. Missing entries are
+// allowed: items expire and are loaded again on the next read.
+func (r CacheReport) Consistent() bool {
+	return len(r.Stale) == 0 && len(r.Extra) == 0
+}
@@ -0,0 +1,184 @@
+package dto
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	dueDateLayout = time.DateOnly
+	// MaxReminders bounds how many reminders one todo can have.
+	MaxReminders = 5
+	// MaxReminderOffset is the earliest a reminder can fire before the todo is due.
+	MaxReminderOffset = 365 * 24 * time.Hour
+)
+
+var ErrInvalidDue = errors.New("invalid todo due date or reminders")
+
+// DueDate is when a todo is due. A date-only due date ("2024-05-01") is due by the end of that day
+// in the local time zone, set from system.timezone, so At holds the following midnight. A nil At
+// means the todo has no due date.
+type DueDate struct {
+	At     *time.Time
+	AllDay bool
+}
+
+// ParseDueDate reads an RFC 3339 timestamp or a date-only due date. An empty string clears it.
+func ParseDueDate(raw string) (DueDate, error) {
+	if raw == "" {
+		return DueDate{}, nil
+	}
+	if day, err := time.ParseInLocation(dueDateLayout, raw, time.Local); err == nil {
+		end := day.AddDate(0, 0, 1)
+		return DueDate{At: &end, AllDay: true}, nil
+	}
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return DueDate{}, ErrInvalidDue
+	}
+	return DueDate{At: &at}, nil
+}
+
+func (d DueDate) IsZero() bool {
+	return d.At == nil
+}
+
+// String formats the due date the way ParseDueDate reads it, in the local time zone.
+func (d DueDate) String() string {
+	switch {
+	case d.At == nil:
+		return ""
+	case d.AllDay:
+		return d.At.In(time.Local).AddDate(0, 0, -1).Format(dueDateLayout)
+	}
+	return d.At.In(time.Local).Format(time.RFC3339Nano)
+}
+
+func (d DueDate) MarshalJSON() ([]byte, error) {
+	if d.At == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *DueDate) UnmarshalJSON(data []byte) error {
+	var raw *string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ErrInvalidDue
+	}
+	if raw == nil {
+		*d = DueDate{}
+		return nil
+	}
+	parsed, err := ParseDueDate(*raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// ReminderOffsets are how long before its due date a todo reminds, as durations such as "24h" or
+// "30m" in JSON.
+type ReminderOffsets []time.Duration
+
+func (r ReminderOffsets) MarshalJSON() ([]byte, error) {
+	offsets := make([]string, 0, len(r))
+	for _, offset := range r {
+		offsets = append(offsets, formatOffset(offset))
+	}
+	return json.Marshal(offsets)
+}
+
+// String lists the offsets the way they are written in JSON, comma separated.
+func (r ReminderOffsets) String() string {
+	offsets := make([]string, 0, len(r))
+	for _, offset := range r {
+		offsets = append(offsets, formatOffset(offset))
+	}
+	return strings.Join(offsets, ",")
+}
+
+// formatOffset drops the zero units time.Duration prints, so 24h0m0s reads 24h.
+func formatOffset(offset time.Duration) string {
+	formatted := offset.String()
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+	return formatted
+}
+
+func (r *ReminderOffsets) UnmarshalJSON(data []byte) error {
+	var raw []string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ErrInvalidDue
+	}
+	offsets := make(ReminderOffsets, 0, len(raw))
+	for _, value := range raw {
+		offset, err := time.ParseDuration(value)
+		if err != nil {
+			return ErrInvalidDue
+		}
+		offsets = append(offsets, offset)
+	}
+	*r = offsets
+	return nil
+}
+
+// Normalize sorts the offsets from the earliest reminder to the latest, drops duplicates and
+// rejects offsets out of range.
+func (r ReminderOffsets) Normalize() (ReminderOffsets, error) {
+	offsets := slices.Compact(r.sorted())
+	if len(offsets) > MaxReminders {
+		return nil, ErrInvalidDue
+	}
+	for _, offset := range offsets {
+		if offset <= 0 || offset > MaxReminderOffset {
+			return nil, ErrInvalidDue
+		}
+	}
+	return offsets, nil
+}
+
+// NextReminder returns the first reminder of todo that fires after now, or nil when none is left.
+func (t Todo) NextReminder(now time.Time) *time.Time {
+	if t.Due.At == nil {
+		return nil
+	}
+	for _, offset := range t.Reminders.sorted() {
+		remindAt := t.Due.At.Add(-offset)
+		if remindAt.After(now) {
+			return &remindAt
+		}
+	}
+	return nil
+}
+
+// ReminderDue returns the latest reminder of todo that has fired by now, with its offset. Reminders
+// missed while the dispatcher was down collapse into it.
+func (t Todo) ReminderDue(now time.Time) (time.Duration, time.Time, bool) {
+	if t.Due.At == nil {
+		return 0, time.Time{}, false
+	}
+	offsets := t.Reminders.sorted()
+	for index := len(offsets) - 1; index >= 0; index-- {
+		remindAt := t.Due.At.Add(-offsets[index])
+		if !remindAt.After(now) {
+			return offsets[index], remindAt, true
+		}
+	}
+	return 0, time.Time{}, false
+}
+
+func (r ReminderOffsets) sorted() ReminderOffsets {
+	offsets := slices.Clone(r)
+	slices.SortFunc(offsets, func(a, b time.Duration) int { return cmp.Compare(b, a) })
+	return offsets
+}
@@ -0,0 +1,8 @@
+package dto
+
+import "errors"
+
+var (
+	ErrTodoNotFound    = errors.New("todo not found")
+	ErrVersionConflict = errors.New("todo version conflict")
+)
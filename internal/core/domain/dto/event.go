@@ -0,0 +1,118 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventTodoCreated       = "TodoCreated"
+	EventTodoStatusChanged = "TodoStatusChanged"
+	EventTodoDeleted       = "TodoDeleted"
+	EventTodoRestored      = "TodoRestored"
+	EventTodoScheduled     = "TodoScheduled"
+	EventTodoReminderDue   = "TodoReminderDue"
+	EventTodoMoved         = "TodoMoved"
+)
+
+type TodoEvent interface {
+	EventType() string
+	AggregateId() string
+}
+
+type TodoCreated struct {
+	Todo Todo `json:"todo"`
+}
+
+func (e TodoCreated) EventType() string   { return EventTodoCreated }
+func (e TodoCreated) AggregateId() string { return e.Todo.Id }
+
+type TodoStatusChanged struct {
+	TodoId  string     `json:"todo_id"`
+	From    TodoStatus `json:"from"`
+	To      TodoStatus `json:"to"`
+	Version int64      `json:"version"`
+}
+
+func (e TodoStatusChanged) EventType() string   { return EventTodoStatusChanged }
+func (e TodoStatusChanged) AggregateId() string { return e.TodoId }
+
+type TodoDeleted struct {
+	TodoId  string `json:"todo_id"`
+	Version int64  `json:"version"`
+}
+
+func (e TodoDeleted) EventType() string   { return EventTodoDeleted }
+func (e TodoDeleted) AggregateId() string { return e.TodoId }
+
+type TodoRestored struct {
+	Todo Todo `json:"todo"`
+}
+
+func (e TodoRestored) EventType() string   { return EventTodoRestored }
+func (e TodoRestored) AggregateId() string { return e.Todo.Id }
+
+type TodoScheduled struct {
+	TodoId    string          `json:"todo_id"`
+	Due       DueDate         `json:"due_at"`
+	Reminders ReminderOffsets `json:"reminders"`
+	Version   int64           `json:"version"`
+}
+
+func (e TodoScheduled) EventType() string   { return EventTodoScheduled }
+func (e TodoScheduled) AggregateId() string { return e.TodoId }
+
+type TodoMoved struct {
+	TodoId   string       `json:"todo_id"`
+	Priority TodoPriority `json:"priority"`
+	Position string       `json:"position"`
+	Version  int64        `json:"version"`
+}
+
+func (e TodoMoved) EventType() string   { return EventTodoMoved }
+func (e TodoMoved) AggregateId() string { return e.TodoId }
+
+// TodoReminderDue is sent through the notifier when a reminder of a todo comes due. Offset is how
+// long before the due date it was set to fire.
+type TodoReminderDue struct {
+	TodoId   string    `json:"todo_id"`
+	Topic    string    `json:"topic"`
+	Due      DueDate   `json:"due_at"`
+	Offset   string    `json:"offset"`
+	RemindAt time.Time `json:"remind_at"`
+}
+
+func (e TodoReminderDue) EventType() string   { return EventTodoReminderDue }
+func (e TodoReminderDue) AggregateId() string { return e.TodoId }
+
+// OutboxEvent is a serialized TodoEvent waiting to be published. Id doubles as the deduplication id
+// consumers use, since delivery is at-least-once.
+type OutboxEvent struct {
+	Id          string     `json:"id" gorm:"primaryKey"`
+	EventType   string     `json:"event_type"`
+	AggregateId string     `json:"aggregate_id"`
+	Payload     string     `json:"payload"`
+	OccurredAt  time.Time  `json:"occurred_at"`
+	PublishedAt *time.Time `json:"published_at"`
+	Attempts    int        `json:"attempts"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+func NewOutboxEvent(event TodoEvent, occurredAt time.Time) (OutboxEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	return OutboxEvent{
+		Id:          uuid.NewString(),
+		EventType:   event.EventType(),
+		AggregateId: event.AggregateId(),
+		Payload:     string(payload),
+		OccurredAt:  occurredAt,
+	}, nil
+}
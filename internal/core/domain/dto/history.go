@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+const (
+	TodoActionCreated   = "created"
+	TodoActionUpdated   = "updated"
+	TodoActionDeleted   = "deleted"
+	TodoActionRestored  = "restored"
+	TodoActionScheduled = "scheduled"
+	TodoActionMoved     = "moved"
+	TodoActionPurged    = "purged"
+)
+
+// TodoHistory is one append-only audit entry. Before is nil for creations and After is nil for purges.
+// Actor is only as trustworthy as its source: names a client claimed start with "unverified:".
+type TodoHistory struct {
+	Id        int64     `json:"id" gorm:"primaryKey"`
+	TodoId    string    `json:"todo_id"`
+	Action    string    `json:"action"`
+	Before    *Todo     `json:"before" gorm:"column:before_state;serializer:json"`
+	After     *Todo     `json:"after" gorm:"column:after_state;serializer:json"`
+	Actor     string    `json:"actor"`
+	RequestId string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TodoHistory) TableName() string {
+	return "todo_history"
+}
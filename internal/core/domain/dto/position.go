@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+)
+
+// positionDigits are the digits of a position key in ascending order. Keys compare as plain
+// strings, so they sort the same bytewise in Go, SQLite and any Postgres collation.
+const positionDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+var (
+	ErrInvalidPosition = errors.New("invalid todo position")
+	// ErrInvalidMove rejects a move whose neighbours are missing, out of order or the todo itself.
+	ErrInvalidMove = errors.New("invalid todo move")
+)
+
+// PositionBetween returns a position key that sorts after lower and before upper, so a todo can be
+// moved without renumbering the others. An empty lower or upper leaves that side open. Keys are
+// base-36 fractions that never end in "0", which leaves room below every key.
+func PositionBetween(lower string, upper string) (string, error) {
+	if !validPosition(lower) || !validPosition(upper) || (lower != "" && upper != "" && lower >= upper) {
+		return "", ErrInvalidPosition
+	}
+	return midpoint(lower, upper), nil
+}
+
+func validPosition(position string) bool {
+	if strings.HasSuffix(position, "0") {
+		return false
+	}
+	for index := 0; index < len(position); index++ {
+		if strings.IndexByte(positionDigits, position[index]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// midpoint returns the shortest key between lower and upper, reading a missing digit of lower as
+// "0" and an empty upper as the end of the range.
+func midpoint(lower string, upper string) string {
+	if upper != "" {
+		n := 0
+		for n < len(upper) && positionDigit(lower, n) == upper[n] {
+			n++
+		}
+		if n > 0 {
+			return upper[:n] + midpoint(positionTail(lower, n), upper[n:])
+		}
+	}
+
+	low, high := 0, len(positionDigits)
+	if lower != "" {
+		low = strings.IndexByte(positionDigits, lower[0])
+	}
+	if upper != "" {
+		high = strings.IndexByte(positionDigits, upper[0])
+	}
+	if high-low > 1 {
+		return string(positionDigits[(low+high+1)/2])
+	}
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+	return string(positionDigits[low]) + midpoint(positionTail(lower, 1), "")
+}
+
+func positionDigit(position string, index int) byte {
+	if index < len(position) {
+		return position[index]
+	}
+	return positionDigits[0]
+}
+
+func positionTail(position string, index int) string {
+	if index < len(position) {
+		return position[index:]
+	}
+	return ""
+}
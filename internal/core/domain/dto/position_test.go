@@ -0,0 +1,50 @@
+package dto_test
+
+import (
+	"testing"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositionBetween(t *testing.T) {
+	testCases := []struct {
+		description string
+		lower       string
+		upper       string
+		expected    string
+		expectedErr error
+	}{
+		{description: "empty list", expected: "i"},
+		{description: "after the last", lower: "i", expected: "r"},
+		{description: "before the first", upper: "i", expected: "9"},
+		{description: "between distant keys", lower: "a", upper: "c", expected: "b"},
+		{description: "between adjacent keys", lower: "a", upper: "b", expected: "ai"},
+		{description: "between a key and its extension", lower: "a", upper: "a01", expected: "a00i"},
+		{description: "before a key starting with 0", upper: "01", expected: "00i"},
+		{description: "keys out of order", lower: "c", upper: "a", expectedErr: dto.ErrInvalidPosition},
+		{description: "equal keys", lower: "a", upper: "a", expectedErr: dto.ErrInvalidPosition},
+		{description: "trailing zero", lower: "a0", expectedErr: dto.ErrInvalidPosition},
+		{description: "digit outside the alphabet", lower: "A", expectedErr: dto.ErrInvalidPosition},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			position, err := dto.PositionBetween(testCase.lower, testCase.upper)
+
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expected, position)
+		})
+	}
+}
+
+func TestPositionBetweenKeepsRoomAfterRepeatedInserts(t *testing.T) {
+	lower, upper := "a", "b"
+	for range 200 {
+		position, err := dto.PositionBetween(lower, upper)
+		assert.NoError(t, err)
+		assert.Less(t, lower, position)
+		assert.Less(t, position, upper)
+		upper = position
+	}
+}
@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+// TodoPriority ranks todos from none to urgent. It is stored as its level and written by name in JSON.
+type TodoPriority int
+
+const (
+	TodoPriorityNone TodoPriority = iota
+	TodoPriorityLow
+	TodoPriorityMedium
+	TodoPriorityHigh
+	TodoPriorityUrgent
+)
+
+var ErrInvalidPriority = errors.New("invalid todo priority")
+
+var todoPriorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+func ParseTodoPriority(name string) (TodoPriority, error) {
+	level := slices.Index(todoPriorityNames, name)
+	if level < 0 {
+		return TodoPriorityNone, ErrInvalidPriority
+	}
+	return TodoPriority(level), nil
+}
+
+func (p TodoPriority) Valid() bool {
+	return p >= TodoPriorityNone && p <= TodoPriorityUrgent
+}
+
+func (p TodoPriority) String() string {
+	if !p.Valid() {
+		return ""
+	}
+	return todoPriorityNames[p]
+}
+
+func (p TodoPriority) MarshalJSON() ([]byte, error) {
+	if !p.Valid() {
+		return nil, ErrInvalidPriority
+	}
+	return json.Marshal(p.String())
+}
+
+func (p *TodoPriority) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return ErrInvalidPriority
+	}
+	priority, err := ParseTodoPriority(name)
+	if err != nil {
+		return err
+	}
+	*p = priority
+	return nil
+}
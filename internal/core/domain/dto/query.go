@@ -0,0 +1,248 @@
+package dto
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTodoLimit = 20
+	MaxTodoLimit     = 100
+	// DefaultTodoSort lists todos by priority, then in their manual order.
+	DefaultTodoSort = "rank"
+)
+
+var ErrInvalidQuery = errors.New("invalid todo query")
+
+type TodoQuery struct {
+	Limit        int
+	Cursor       string
+	Status       TodoStatus
+	Created      TimeRange
+	Updated      TimeRange
+	Completed    TimeRange
+	Sort         string
+	IncludeTotal bool
+}
+
+// TimeRange bounds a timestamp to [After, Before). A zero bound leaves that side open.
+type TimeRange struct {
+	After  time.Time
+	Before time.Time
+}
+
+func (r TimeRange) IsZero() bool {
+	return r.After.IsZero() && r.Before.IsZero()
+}
+
+func (r TimeRange) Contains(t time.Time) bool {
+	return (r.After.IsZero() || !t.Before(r.After)) && (r.Before.IsZero() || t.Before(r.Before))
+}
+
+func (r TimeRange) String() string {
+	return r.After.UTC().Format(time.RFC3339Nano) + "/" + r.Before.UTC().Format(time.RFC3339Nano)
+}
+
+type TodoPage struct {
+	Todos      []Todo `json:"todos"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// TodoSortField is a column a todo list can be sorted by. Present is set for columns some todos
+// leave empty; a list sorted by one holds only the todos that have it.
+type TodoSortField struct {
+	Column  string
+	Value   func(Todo) any
+	Present func(Todo) bool
+}
+
+// TodoSortFields lists the fields a todo list can be sorted by, keyed by their json name.
+var TodoSortFields = map[string]TodoSortField{
+	"id":          {Column: "id", Value: func(t Todo) any { return t.Id }},
+	"topic":       {Column: "topic", Value: func(t Todo) any { return t.Topic }},
+	"description": {Column: "description", Value: func(t Todo) any { return t.Description }},
+	"status":      {Column: "status", Value: func(t Todo) any { return string(t.Status) }},
+	"priority":    {Column: "priority", Value: func(t Todo) any { return int64(t.Priority) }},
+	"position":    {Column: "position", Value: func(t Todo) any { return t.Position }},
+	"rank":        {Column: rankColumn, Value: func(t Todo) any { return rank(t) }},
+	"version":     {Column: "version", Value: func(t Todo) any { return t.Version }},
+	"created_at":  {Column: "created_at", Value: func(t Todo) any { return t.CreatedAt }},
+	"updated_at":  {Column: "updated_at", Value: func(t Todo) any { return t.UpdatedAt }},
+	"completed_at": {
+		Column:  "completed_at",
+		Value:   func(t Todo) any { return completedAt(t) },
+		Present: func(t Todo) bool { return t.CompletedAt != nil },
+	},
+}
+
+// rankColumn orders todos by priority, the most urgent first, then by position. Priority is
+// inverted into a single leading digit so one string key, and one cursor value, holds both.
+var rankColumn = "(CAST(" + strconv.Itoa(int(TodoPriorityUrgent)) + " - priority AS TEXT) || position)"
+
+func rank(t Todo) string {
+	return strconv.Itoa(int(TodoPriorityUrgent-t.Priority)) + t.Position
+}
+
+func completedAt(t Todo) time.Time {
+	if t.CompletedAt == nil {
+		return time.Time{}
+	}
+	return *t.CompletedAt
+}
+
+// Normalize applies defaults and rejects unknown sort fields or statuses, out of range limits and
+// empty time ranges.
+func (q TodoQuery) Normalize() (TodoQuery, error) {
+	if q.Limit == 0 {
+		q.Limit = DefaultTodoLimit
+	}
+	if q.Limit < 0 || q.Limit > MaxTodoLimit {
+		return q, ErrInvalidQuery
+	}
+	if q.Status != "" && !q.Status.Valid() {
+		return q, ErrInvalidQuery
+	}
+	for _, r := range []TimeRange{q.Created, q.Updated, q.Completed} {
+		if !r.After.IsZero() && !r.Before.IsZero() && !r.After.Before(r.Before) {
+			return q, ErrInvalidQuery
+		}
+	}
+	if q.Sort == "" {
+		q.Sort = DefaultTodoSort
+	}
+	if _, ok := TodoSortFields[strings.TrimPrefix(q.Sort, "-")]; !ok {
+		return q, ErrInvalidQuery
+	}
+	return q, nil
+}
+
+// Matches reports whether todo belongs in a list for q: it is not trashed, passes the status and time
+// filters, and has a value for the sort field.
+func (q TodoQuery) Matches(todo Todo) bool {
+	if todo.DeletedAt.Valid || (q.Status != "" && todo.Status != q.Status) {
+		return false
+	}
+	if !q.Created.Contains(todo.CreatedAt) || !q.Updated.Contains(todo.UpdatedAt) {
+		return false
+	}
+	if !q.Completed.IsZero() && (todo.CompletedAt == nil || !q.Completed.Contains(*todo.CompletedAt)) {
+		return false
+	}
+	field, _ := q.SortField()
+	return field.Present == nil || field.Present(todo)
+}
+
+// SortField returns the field named by Sort and whether it is descending ("-topic").
+func (q TodoQuery) SortField() (TodoSortField, bool) {
+	name := strings.TrimPrefix(q.Sort, "-")
+	return TodoSortFields[name], name != q.Sort
+}
+
+// Compare orders two todos the way a page for q is sorted, breaking ties by id.
+func (q TodoQuery) Compare(a Todo, b Todo) int {
+	field, desc := q.SortField()
+	order := compareValues(field.Value(a), field.Value(b))
+	if order == 0 {
+		order = cmp.Compare(a.Id, b.Id)
+	}
+	if desc {
+		return -order
+	}
+	return order
+}
+
+// After reports whether todo sorts after the cursor position.
+func (q TodoQuery) After(todo Todo, cursor TodoCursor) bool {
+	field, desc := q.SortField()
+	order := compareValues(field.Value(todo), cursor.Value)
+	if order == 0 {
+		order = cmp.Compare(todo.Id, cursor.Id)
+	}
+	if desc {
+		return order < 0
+	}
+	return order > 0
+}
+
+func compareValues(a any, b any) int {
+	switch a := a.(type) {
+	case string:
+		b, _ := b.(string)
+		return cmp.Compare(a, b)
+	case int64:
+		b, _ := b.(int64)
+		return cmp.Compare(a, b)
+	case time.Time:
+		b, _ := b.(time.Time)
+		return a.Compare(b)
+	}
+	return 0
+}
+
+// TodoCursor is the position after the last todo of a page: its sort value, with the id breaking ties.
+type TodoCursor struct {
+	Sort  string
+	Value any
+	Id    string
+}
+
+type todoCursorPayload struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	Id    string          `json:"id"`
+}
+
+func EncodeTodoCursor(sort string, last Todo) (string, error) {
+	field, ok := TodoSortFields[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return "", ErrInvalidQuery
+	}
+	value, err := json.Marshal(field.Value(last))
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(todoCursorPayload{Sort: sort, Value: value, Id: last.Id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeTodoCursor parses a cursor produced for the same sort, restoring the value to the field's type.
+func DecodeTodoCursor(sort string, cursor string) (TodoCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return TodoCursor{}, ErrInvalidQuery
+	}
+	var payload todoCursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Sort != sort {
+		return TodoCursor{}, ErrInvalidQuery
+	}
+
+	field, ok := TodoSortFields[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return TodoCursor{}, ErrInvalidQuery
+	}
+	value := reflect.New(reflect.TypeOf(field.Value(Todo{})))
+	if err := json.Unmarshal(payload.Value, value.Interface()); err != nil {
+		return TodoCursor{}, ErrInvalidQuery
+	}
+
+	return TodoCursor{Sort: payload.Sort, Value: value.Elem().Interface(), Id: payload.Id}, nil
+}
+
+// CachedTodoPage is the cached form of a list page: the ordered ids of its todos, whose bodies are
+// cached under their own keys, and the time after which the page is refreshed in the background.
+type CachedTodoPage struct {
+	Ids        []string  `json:"ids"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Total      *int64    `json:"total,omitempty"`
+	FreshUntil time.Time `json:"fresh_until"`
+}
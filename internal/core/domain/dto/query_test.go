@@ -0,0 +1,41 @@
+package dto_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoCursorRoundTrip(t *testing.T) {
+	completedAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	last := dto.Todo{Id: "1", Topic: "Complete Project", Status: dto.TodoStatusDone, Priority: dto.TodoPriorityHigh, Position: "i", Version: 7, CompletedAt: &completedAt}
+
+	testCases := []struct {
+		description string
+		sort        string
+		expected    any
+	}{
+		{description: "string field", sort: "topic", expected: "Complete Project"},
+		{description: "descending number field", sort: "-version", expected: int64(7)},
+		{description: "status field", sort: "status", expected: "done"},
+		{description: "optional time field", sort: "completed_at", expected: completedAt},
+		{description: "priority and position", sort: "rank", expected: "1i"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			encoded, err := dto.EncodeTodoCursor(testCase.sort, last)
+			assert.NoError(t, err)
+
+			cursor, err := dto.DecodeTodoCursor(testCase.sort, encoded)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, cursor.Value)
+			assert.Equal(t, "1", cursor.Id)
+
+			_, err = dto.DecodeTodoCursor("id", encoded)
+			assert.ErrorIs(t, err, dto.ErrInvalidQuery)
+		})
+	}
+}
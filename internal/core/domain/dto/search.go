@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+type TodoSearchQuery struct {
+	Text  string
+	Limit int
+}
+
+// TodoSearchResult carries snippets as HTML: the todo's text is escaped and only the matches are
+// wrapped in <mark>.
+type TodoSearchResult struct {
+	Todo               Todo    `json:"todo"`
+	Rank               float64 `json:"rank"`
+	TopicSnippet       string  `json:"topic_snippet"`
+	DescriptionSnippet string  `json:"description_snippet"`
+}
+
+// Normalize trims the search text and applies the list limit defaults.
+func (q TodoSearchQuery) Normalize() (TodoSearchQuery, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	if q.Text == "" {
+		return q, ErrInvalidQuery
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultTodoLimit
+	}
+	if q.Limit < 0 || q.Limit > MaxTodoLimit {
+		return q, ErrInvalidQuery
+	}
+	return q, nil
+}
+
+// Terms splits the search text into lower-case words.
+func (q TodoSearchQuery) Terms() []string {
+	return strings.Fields(strings.ToLower(q.Text))
+}
+
+// Match is the substring fallback for stores without full-text search. Every term must appear in
+// the topic or description; topic hits rank higher.
+func (q TodoSearchQuery) Match(todo Todo) (TodoSearchResult, bool) {
+	terms := q.Terms()
+	if len(terms) == 0 {
+		return TodoSearchResult{}, false
+	}
+
+	topic := strings.ToLower(todo.Topic)
+	description := strings.ToLower(todo.Description)
+	var rank float64
+	for _, term := range terms {
+		inTopic := strings.Contains(topic, term)
+		inDescription := strings.Contains(description, term)
+		if !inTopic && !inDescription {
+			return TodoSearchResult{}, false
+		}
+		if inTopic {
+			rank += 1
+		}
+		if inDescription {
+			rank += 0.4
+		}
+	}
+
+	return TodoSearchResult{
+		Todo:               todo,
+		Rank:               rank / float64(len(terms)),
+		TopicSnippet:       highlight(todo.Topic, terms),
+		DescriptionSnippet: highlight(todo.Description, terms),
+	}, true
+}
+
+// highlight escapes text as HTML and wraps every match of terms in <mark>.
+func highlight(text string, terms []string) string {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	pattern := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+
+	var snippet strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(text, -1) {
+		snippet.WriteString(html.EscapeString(text[last:match[0]]))
+		snippet.WriteString("<mark>" + html.EscapeString(text[match[0]:match[1]]) + "</mark>")
+		last = match[1]
+	}
+	snippet.WriteString(html.EscapeString(text[last:]))
+	return snippet.String()
+}
@@ -0,0 +1,39 @@
+package dto_test
+
+import (
+	"testing"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoSearchQueryMatchSnippets(t *testing.T) {
+	testCases := []struct {
+		description         string
+		todo                dto.Todo
+		expectedTopic       string
+		expectedDescription string
+	}{
+		{
+			description:   "matches are wrapped in mark",
+			todo:          dto.Todo{Topic: "Complete Project"},
+			expectedTopic: "Complete <mark>Project</mark>",
+		},
+		{
+			description:         "markup in the todo is escaped",
+			todo:                dto.Todo{Topic: `<script>alert("project")</script>`, Description: "R&D project's <b>plan</b>"},
+			expectedTopic:       `&lt;script&gt;alert(&#34;<mark>project</mark>&#34;)&lt;/script&gt;`,
+			expectedDescription: "R&amp;D <mark>project</mark>&#39;s &lt;b&gt;plan&lt;/b&gt;",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			result, ok := dto.TodoSearchQuery{Text: "project"}.Match(testCase.todo)
+
+			assert.True(t, ok)
+			assert.Equal(t, testCase.expectedTopic, result.TopicSnippet)
+			assert.Equal(t, testCase.expectedDescription, result.DescriptionSnippet)
+		})
+	}
+}
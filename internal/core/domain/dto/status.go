@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// TodoStatus is where a todo is in its workflow. Only the values below are stored.
+type TodoStatus string
+
+const (
+	TodoStatusPending    TodoStatus = "pending"
+	TodoStatusInProgress TodoStatus = "in_progress"
+	TodoStatusDone       TodoStatus = "done"
+	TodoStatusReopened   TodoStatus = "reopened"
+)
+
+var (
+	ErrInvalidStatus = errors.New("invalid todo status")
+	// ErrIllegalTransition is matched by every *StatusTransitionError.
+	ErrIllegalTransition = errors.New("illegal todo status transition")
+)
+
+// todoTransitions lists the statuses each status may move to. Moving to the current status is
+// always allowed and changes nothing.
+var todoTransitions = map[TodoStatus][]TodoStatus{
+	TodoStatusPending:    {TodoStatusInProgress, TodoStatusDone},
+	TodoStatusInProgress: {TodoStatusPending, TodoStatusDone},
+	TodoStatusDone:       {TodoStatusReopened},
+	TodoStatusReopened:   {TodoStatusInProgress, TodoStatusDone},
+}
+
+func (s TodoStatus) Valid() bool {
+	_, ok := todoTransitions[s]
+	return ok
+}
+
+// Next returns the statuses s may move to.
+func (s TodoStatus) Next() []TodoStatus {
+	return slices.Clone(todoTransitions[s])
+}
+
+// StatusTransitionError rejects moving a todo from one status to another the workflow does not allow.
+type StatusTransitionError struct {
+	From    TodoStatus
+	To      TodoStatus
+	Allowed []TodoStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("todo status cannot move from %q to %q", e.From, e.To)
+}
+
+func (e *StatusTransitionError) Is(target error) bool {
+	return target == ErrIllegalTransition
+}
+
+// CheckTransition returns ErrInvalidStatus when to is unknown and a *StatusTransitionError when
+// the workflow does not allow moving from to to.
+func CheckTransition(from TodoStatus, to TodoStatus) error {
+	if !to.Valid() {
+		return ErrInvalidStatus
+	}
+	if from == to || slices.Contains(todoTransitions[from], to) {
+		return nil
+	}
+	return &StatusTransitionError{From: from, To: to, Allowed: from.Next()}
+}
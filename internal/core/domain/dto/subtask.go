@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidParent rejects storing a subtask whose parent is missing, trashed, or done while the
+	// subtask is not.
+	ErrInvalidParent = errors.New("invalid todo parent")
+	// ErrOpenSubtasks is matched by every *OpenSubtasksError.
+	ErrOpenSubtasks = errors.New("todo has open subtasks")
+)
+
+// TodoProgress is how much of a todo's subtree is done, counting every live descendant.
+type TodoProgress struct {
+	Done    int `json:"done"`
+	Total   int `json:"total"`
+	Percent int `json:"percent"`
+}
+
+func NewTodoProgress(done int, total int) TodoProgress {
+	progress := TodoProgress{Done: done, Total: total}
+	if total > 0 {
+		progress.Percent = done * 100 / total
+	}
+	return progress
+}
+
+// OpenSubtasksError rejects completing a todo while subtasks below it are not done, deleting it
+// while subtasks below it are not trashed, or purging it while subtasks remain. Open lists their ids.
+type OpenSubtasksError struct {
+	TodoId string
+	Open   []string
+}
+
+func (e *OpenSubtasksError) Error() string {
+	return fmt.Sprintf("todo %q has %d open subtasks", e.TodoId, len(e.Open))
+}
+
+func (e *OpenSubtasksError) Is(target error) bool {
+	return target == ErrOpenSubtasks
+}
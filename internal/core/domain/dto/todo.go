@@ -1,22 +1,113 @@
 package dto
 
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Todo timestamps are set by the service and repositories, never taken from a request. CompletedAt
+// is set while the status is done. Position is the todo's manual order within its priority, a key
+// from PositionBetween. ParentId makes the todo a subtask; it is set on create and never changes.
+// Progress is computed on every read and never stored or cached. NextReminderAt is kept by the
+// repository for the reminder dispatcher and is not part of the todo's representation.
 type Todo struct {
-	Id          string `json:"id" gorm:"primaryKey;"`
-	Topic       string `json:"topic"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
+	Id             string          `json:"id" gorm:"primaryKey;"`
+	ParentId       *string         `json:"parent_id" gorm:"index"`
+	Topic          string          `json:"topic"`
+	Description    string          `json:"description"`
+	Status         TodoStatus      `json:"status"`
+	Priority       TodoPriority    `json:"priority" gorm:"not null;default:0"`
+	Position       string          `json:"position" gorm:"not null"`
+	Version        int64           `json:"version" gorm:"not null;default:1"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"not null"`
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"not null"`
+	CompletedAt    *time.Time      `json:"completed_at"`
+	Due            DueDate         `json:"due_at" gorm:"embedded;embeddedPrefix:due_"`
+	Reminders      ReminderOffsets `json:"reminders" gorm:"serializer:json"`
+	DeletedAt      gorm.DeletedAt  `json:"deleted_at" gorm:"index"`
+	NextReminderAt *time.Time      `json:"-" msgpack:"-"`
+	Progress       *TodoProgress   `json:"progress,omitempty" gorm:"-" msgpack:"-"`
+}
+
+// MarshalJSON writes the timestamps in RFC 3339 in the local time zone, set from system.timezone,
+// whichever zone the database or cache returned them in.
+func (t Todo) MarshalJSON() ([]byte, error) {
+	type todo Todo
+	local := todo(t)
+	local.CreatedAt = inLocal(local.CreatedAt)
+	local.UpdatedAt = inLocal(local.UpdatedAt)
+	if local.CompletedAt != nil {
+		completedAt := inLocal(*local.CompletedAt)
+		local.CompletedAt = &completedAt
+	}
+	return json.Marshal(local)
+}
+
+// inLocal leaves the zero time alone, which would otherwise print with the zone's historical offset.
+func inLocal(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(time.Local)
+}
+
+// Stamp sets the timestamps of a todo written at now: UpdatedAt always, CreatedAt on its first
+// write, and CompletedAt when it becomes done or clears it when it leaves done.
+func (t *Todo) Stamp(now time.Time) {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	t.UpdatedAt = now
+	switch {
+	case t.Status != TodoStatusDone:
+		t.CompletedAt = nil
+	case t.CompletedAt == nil:
+		t.CompletedAt = &now
+	}
 }
 
 type TodoInputSave struct {
-	Topic       string `json:"topic" validate:"required"`
-	Description string `json:"description" validate:"required"`
-	Status      string `json:"status" validate:"required"`
+	ParentId    *string         `json:"parent_id"`
+	Topic       string          `json:"topic" validate:"required"`
+	Description string          `json:"description" validate:"required"`
+	Status      TodoStatus      `json:"status" validate:"required,todostatus"`
+	Priority    TodoPriority    `json:"priority"`
+	Due         DueDate         `json:"due_at"`
+	Reminders   ReminderOffsets `json:"reminders"`
 }
 
+// ExpectedVersion is taken from If-Match; zero skips the version check.
 type TodoInputUpdateStatus struct {
-	Id     string `json:"id" validate:"required"`
-	Status string `json:"status" validate:"required"`
+	Id              string     `json:"id" validate:"required"`
+	Status          TodoStatus `json:"status" validate:"required,todostatus"`
+	ExpectedVersion int64      `json:"-"`
 }
+
 type TodoInputDelete struct {
-	Id string `json:"id" validate:"required"`
+	Id              string `json:"id" validate:"required"`
+	ExpectedVersion int64  `json:"-"`
+}
+
+// TodoInputSchedule sets when a todo is due and how long before that it reminds. A null due_at
+// clears the due date; reminders need one.
+type TodoInputSchedule struct {
+	Id              string          `json:"-"`
+	Due             DueDate         `json:"due_at"`
+	Reminders       ReminderOffsets `json:"reminders"`
+	ExpectedVersion int64           `json:"-"`
+}
+
+// TodoInputMove places a todo right after After and right before Before, the ids of its new
+// neighbours; leaving one out places it next to the other. The todo takes the neighbours' priority,
+// which a set Priority must match; with no neighbours it moves to the end of Priority. Position is
+// computed by the service from the neighbours.
+type TodoInputMove struct {
+	Id              string        `json:"-"`
+	After           string        `json:"after"`
+	Before          string        `json:"before"`
+	Priority        *TodoPriority `json:"priority"`
+	Position        string        `json:"-"`
+	ExpectedVersion int64         `json:"-"`
 }
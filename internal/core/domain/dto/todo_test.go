@@ -0,0 +1,59 @@
+package dto_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoMarshalJSONInLocalZone(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+7", 7*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	stored := time.Date(2024, 5, 1, 20, 30, 0, 0, time.UTC)
+	completed := stored.In(time.FixedZone("UTC-5", -5*60*60))
+
+	testCases := []struct {
+		description string
+		todo        dto.Todo
+		expected    map[string]any
+	}{
+		{
+			description: "Timestamps in any zone are written in the local one.",
+			todo:        dto.Todo{Id: "1", CreatedAt: stored, UpdatedAt: stored, CompletedAt: &completed},
+			expected: map[string]any{
+				"created_at":   "2024-05-02T03:30:00+07:00",
+				"updated_at":   "2024-05-02T03:30:00+07:00",
+				"completed_at": "2024-05-02T03:30:00+07:00",
+			},
+		},
+		{
+			description: "Zero and missing timestamps are left alone.",
+			todo:        dto.Todo{Id: "1"},
+			expected: map[string]any{
+				"created_at":   "0001-01-01T00:00:00Z",
+				"updated_at":   "0001-01-01T00:00:00Z",
+				"completed_at": nil,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			data, err := json.Marshal(testCase.todo)
+
+			// Assert
+			assert.NoError(t, err)
+			var written map[string]any
+			assert.NoError(t, json.Unmarshal(data, &written))
+			for field, expected := range testCase.expected {
+				assert.Equal(t, expected, written[field], field)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package dto
+
+// BufferedWrite is a todo write accepted by the write-behind cache and not yet in the repository.
+// It carries the todo as the write left it, with the audit entry and event to store alongside, so
+// it can be flushed without reading anything else. Id is assigned by the write buffer.
+type BufferedWrite struct {
+	Id      string       `json:"-"`
+	Todo    Todo         `json:"todo"`
+	History TodoHistory  `json:"history"`
+	Event   *OutboxEvent `json:"event,omitempty"`
+}
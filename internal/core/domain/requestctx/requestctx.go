@@ -0,0 +1,43 @@
+package requestctx
+
+import "context"
+
+const (
+	AnonymousActor = "anonymous"
+	SystemActor    = "system"
+	// UnverifiedActorPrefix marks an actor the client named but nothing authenticated.
+	UnverifiedActorPrefix = "unverified:"
+)
+
+type contextKey int
+
+const (
+	requestIdKey contextKey = iota
+	actorKey
+)
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+func RequestId(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey).(string)
+	return requestId
+}
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// UnverifiedActor labels a client-supplied name so audit entries cannot pass it off as verified.
+func UnverifiedActor(name string) string {
+	return UnverifiedActorPrefix + name
+}
+
+// Actor returns who is acting in ctx, or AnonymousActor when nobody was recorded.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+)
+
+type CacheChecker interface {
+	Check(ctx context.Context, repair bool) (dto.CacheReport, error)
+}
+
+type cacheCheckerImpl struct {
+	repo  repository.TodoRepository
+	cache cache.Cache
+	codec codec.Codec
+	keys  todoKeys
+	ttl   CacheTTL
+	// writeBehind means entries ahead of the repository may be writes waiting to be flushed.
+	writeBehind bool
+}
+
+func NewCacheChecker(repo repository.TodoRepository, cache cache.Cache, codec codec.Codec, schema string, ttl CacheTTL, write CacheWrite) CacheChecker {
+	if ttl.Item <= 0 {
+		ttl.Item = defaultItemTTL
+	}
+	return &cacheCheckerImpl{
+		repo:        repo,
+		cache:       cache,
+		codec:       codec,
+		keys:        newTodoKeys(codec, schema),
+		ttl:         ttl,
+		writeBehind: write.Strategy == CacheWriteBehind,
+	}
+}
+
+// Check compares every cached todo with the repository, including trashed todos. With repair it
+// rewrites missing and stale entries, deletes extra ones and drops every cached list page.
+// Writes racing the check can make an entry look stale or extra, so those are confirmed against
+// the repository again before being repaired. Under write-behind, entries ahead of or missing from
+// the repository are reported as pending and left alone.
+func (c *cacheCheckerImpl) Check(ctx context.Context, repair bool) (dto.CacheReport, error) {
+	todos, err := c.allTodos(ctx)
+	if err != nil {
+		return dto.CacheReport{}, err
+	}
+
+	keys, err := c.cache.Scan(ctx, c.keys.itemPrefix())
+	if err != nil {
+		return dto.CacheReport{}, err
+	}
+	cached, err := c.cache.MGet(ctx, keys)
+	if err != nil {
+		return dto.CacheReport{}, err
+	}
+
+	report := dto.CacheReport{Checked: len(todos), Cached: len(cached), Missing: []string{}, Stale: []string{}, Extra: []string{}, Pending: []string{}}
+	byId := make(map[string]dto.Todo, len(todos))
+	for _, todo := range todos {
+		byId[todo.Id] = todo
+		value, ok := cached[c.keys.item(todo.Id)]
+		if !ok {
+			report.Missing = append(report.Missing, todo.Id)
+			continue
+		}
+		version, err := cache.ValueVersion(value)
+		switch {
+		case err == nil && version > todo.Version && c.writeBehind:
+			report.Pending = append(report.Pending, todo.Id)
+		case err != nil || version != todo.Version:
+			report.Stale = append(report.Stale, todo.Id)
+		}
+	}
+	for key, value := range cached {
+		id := strings.TrimPrefix(key, c.keys.itemPrefix())
+		if _, ok := byId[id]; ok {
+			continue
+		}
+		version, err := cache.ValueVersion(value)
+		switch {
+		case err == nil && version == math.MaxInt64:
+		case err == nil && c.writeBehind:
+			report.Pending = append(report.Pending, id)
+		default:
+			report.Extra = append(report.Extra, id)
+		}
+	}
+
+	if !repair {
+		return report, nil
+	}
+	if err := c.repair(ctx, report, byId); err != nil {
+		return report, err
+	}
+	report.Repaired = true
+	return report, nil
+}
+
+func (c *cacheCheckerImpl) repair(ctx context.Context, report dto.CacheReport, byId map[string]dto.Todo) error {
+	for _, id := range slices.Concat(report.Stale, report.Extra) {
+		current, err := c.repo.FindByIdForUpdate(ctx, id)
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			if err := c.cache.Del(ctx, c.keys.item(id)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		// SetIfNewer cannot lower a version, so an entry ahead of the repository is dropped first.
+		if err := c.cache.Del(ctx, c.keys.item(id)); err != nil {
+			return err
+		}
+		byId[id] = current
+	}
+
+	values := map[string]string{}
+	for _, id := range slices.Concat(report.Missing, report.Stale) {
+		value, err := encodeTodo(c.codec, byId[id])
+		if err != nil {
+			return err
+		}
+		values[c.keys.item(id)] = value
+	}
+	if err := c.cache.SetIfNewer(ctx, values, c.ttl.Item); err != nil {
+		return err
+	}
+	return invalidateLists(ctx, c.cache, c.keys)
+}
+
+// allTodos reads every todo, live and trashed, from the repository.
+func (c *cacheCheckerImpl) allTodos(ctx context.Context) ([]dto.Todo, error) {
+	query, err := dto.TodoQuery{Limit: dto.MaxTodoLimit}.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	todos := []dto.Todo{}
+	for {
+		page, err := c.repo.FindAll(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, page.Todos...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	trash, err := c.repo.FindTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(todos, trash...), nil
+}
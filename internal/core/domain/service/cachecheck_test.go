@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/serialization"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCacheChecker(t *testing.T) {
+	testCases := []struct {
+		description string
+		repair      bool
+	}{
+		{description: "Check reports drift"},
+		{description: "Check repairs drift", repair: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			todoRepo := repository.NewTodoRepositoryMock()
+			todoCache := cache.NewRedisCacheMock()
+
+			fresh := dto.Todo{Id: "fresh", Version: 2}
+			stale := dto.Todo{Id: "stale", Version: 3}
+			missing := dto.Todo{Id: "missing", Version: 1}
+			todoRepo.On("FindAll", mock.Anything, mock.Anything).Return(dto.TodoPage{Todos: []dto.Todo{fresh, stale}}, nil)
+			todoRepo.On("FindTrash", mock.Anything).Return([]dto.Todo{missing}, nil)
+
+			keys := []string{"todos:json:v1:item:fresh", "todos:json:v1:item:stale", "todos:json:v1:item:extra", "todos:json:v1:item:purged"}
+			todoCache.On("Scan", mock.Anything, "todos:json:v1:item:").Return(keys, nil)
+			todoCache.On("MGet", mock.Anything, keys).Return(map[string]string{
+				"todos:json:v1:item:fresh":  `2:{"id":"fresh","version":2}`,
+				"todos:json:v1:item:stale":  `1:{"id":"stale","version":1}`,
+				"todos:json:v1:item:extra":  `1:{"id":"extra","version":1}`,
+				"todos:json:v1:item:purged": `9223372036854775807:{"id":"purged","version":9223372036854775807}`,
+			}, nil)
+
+			if testCase.repair {
+				todoRepo.On("FindByIdForUpdate", mock.Anything, "stale").Return(stale, nil)
+				todoRepo.On("FindByIdForUpdate", mock.Anything, "extra").Return(dto.Todo{}, dto.ErrTodoNotFound)
+				todoCache.On("Del", mock.Anything, []string{"todos:json:v1:item:stale"}).Return(nil)
+				todoCache.On("Del", mock.Anything, []string{"todos:json:v1:item:extra"}).Return(nil)
+				todoCache.On("SetIfNewer", mock.Anything, mock.MatchedBy(func(values map[string]string) bool {
+					_, hasMissing := values["todos:json:v1:item:missing"]
+					_, hasStale := values["todos:json:v1:item:stale"]
+					return len(values) == 2 && hasMissing && hasStale
+				}), mock.Anything).Return(nil)
+				todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			checker := service.NewCacheChecker(todoRepo, todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{})
+
+			// Act
+			report, err := checker.Check(context.Background(), testCase.repair)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, 3, report.Checked)
+			assert.Equal(t, 4, report.Cached)
+			assert.Equal(t, []string{"missing"}, report.Missing)
+			assert.Equal(t, []string{"stale"}, report.Stale)
+			assert.Equal(t, []string{"extra"}, report.Extra)
+			assert.Equal(t, []string{}, report.Pending)
+			assert.Equal(t, testCase.repair, report.Repaired)
+			assert.False(t, report.Consistent())
+
+			todoRepo.AssertExpectations(t)
+			todoCache.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+)
+
+const (
+	todoKeyPrefix       = "todos:"
+	liveKeyPrefix       = todoKeyPrefix + "live:"
+	schemaVersionLength = 8
+)
+
+// CacheSchemaVersion fingerprints the fields of the cached todo types. It is part of every todo
+// cache key, so a deploy that changes them reads and writes new keys instead of decoding values
+// of another shape, and replicas of different versions never overwrite each other's entries.
+func CacheSchemaVersion() string {
+	hash := sha1.New()
+	for _, cached := range []reflect.Type{reflect.TypeOf(dto.Todo{}), reflect.TypeOf(dto.CachedTodoPage{})} {
+		writeShape(hash, cached)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:schemaVersionLength]
+}
+
+// writeShape writes the fields of a struct type, descending into the structs of package dto.
+func writeShape(w io.Writer, t reflect.Type) {
+	fmt.Fprintf(w, "%s{", t)
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		fmt.Fprintf(w, "%s %s %q;", field.Name, field.Type, field.Tag)
+		if field.Type.Kind() == reflect.Struct && field.Type.PkgPath() == t.PkgPath() {
+			writeShape(w, field.Type)
+		}
+	}
+	fmt.Fprint(w, "}")
+}
+
+// todoKeys names the todo cache entries of one namespace: the codec and schema version they are
+// written with. Rolling either never reads a value in a format it does not expect, Redis needs no
+// flush, and the namespaces left behind are collected once no instance uses them.
+type todoKeys struct {
+	namespace string
+	prefix    string
+}
+
+// newTodoKeys uses CacheSchemaVersion when schema is empty.
+func newTodoKeys(c codec.Codec, schema string) todoKeys {
+	if schema == "" {
+		schema = CacheSchemaVersion()
+	}
+	namespace := c.Name() + ":v" + schema
+	return todoKeys{namespace: namespace, prefix: todoKeyPrefix + namespace + ":"}
+}
+
+// live marks the namespace as used by a running instance.
+func (k todoKeys) live() string {
+	return liveKeyPrefix + k.namespace
+}
+
+func (k todoKeys) item(id string) string {
+	return k.itemPrefix() + id
+}
+
+func (k todoKeys) itemPrefix() string {
+	return k.prefix + "item:"
+}
+
+func (k todoKeys) generation() string {
+	return k.prefix + "gen"
+}
+
+func (k todoKeys) list(generation string, hash string) string {
+	return k.prefix + "list:" + generation + ":" + hash
+}
+
+// encodeTodo encodes todo in the versioned form SetIfNewer compares.
+func encodeTodo(c codec.Codec, todo dto.Todo) (string, error) {
+	data, err := c.Marshal(todo)
+	if err != nil {
+		return "", err
+	}
+	return cache.Versioned(todo.Version, data), nil
+}
+
+func decodeTodo(c codec.Codec, value string) (dto.Todo, error) {
+	payload, err := cache.ValuePayload(value)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	var todo dto.Todo
+	if err := c.Unmarshal(payload, &todo); err != nil {
+		return dto.Todo{}, err
+	}
+	return todo, nil
+}
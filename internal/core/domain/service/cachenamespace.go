@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+)
+
+// collectBatchSize bounds how many keys one delete sends.
+const collectBatchSize = 1000
+
+// CacheNamespaces tracks which todo cache namespaces running instances use and deletes the rest.
+type CacheNamespaces interface {
+	// Heartbeat marks this instance's namespace as in use for lease.
+	Heartbeat(ctx context.Context, lease time.Duration) error
+	// CollectGarbage deletes every todo key outside the namespaces marked as in use, including
+	// keys written before namespaces existed, and returns how many it deleted.
+	CollectGarbage(ctx context.Context) (int, error)
+}
+
+type cacheNamespacesImpl struct {
+	cache cache.Cache
+	keys  todoKeys
+}
+
+func NewCacheNamespaces(cache cache.Cache, codec codec.Codec, schema string) CacheNamespaces {
+	return &cacheNamespacesImpl{cache: cache, keys: newTodoKeys(codec, schema)}
+}
+
+func (n *cacheNamespacesImpl) Heartbeat(ctx context.Context, lease time.Duration) error {
+	return n.cache.Set(ctx, n.keys.live(), time.Now().Format(time.RFC3339), lease)
+}
+
+func (n *cacheNamespacesImpl) CollectGarbage(ctx context.Context) (int, error) {
+	markers, err := n.cache.Scan(ctx, liveKeyPrefix)
+	if err != nil {
+		return 0, err
+	}
+	live := map[string]bool{n.keys.namespace: true}
+	for _, marker := range markers {
+		live[strings.TrimPrefix(marker, liveKeyPrefix)] = true
+	}
+
+	keys, err := n.cache.Scan(ctx, todoKeyPrefix)
+	if err != nil {
+		return 0, err
+	}
+	garbage := []string{}
+	for _, key := range keys {
+		if strings.HasPrefix(key, liveKeyPrefix) {
+			continue
+		}
+		if namespace, ok := keyNamespace(key); ok && live[namespace] {
+			continue
+		}
+		garbage = append(garbage, key)
+	}
+
+	deleted := 0
+	for start := 0; start < len(garbage); start += collectBatchSize {
+		batch := garbage[start:min(start+collectBatchSize, len(garbage))]
+		if err := n.cache.Del(ctx, batch...); err != nil {
+			return deleted, err
+		}
+		deleted += len(batch)
+	}
+	return deleted, nil
+}
+
+// keyNamespace reads the namespace of a key laid out as todos:<codec>:v<schema>:<rest>. Keys from
+// before namespaces, such as todos:item:<id>, report none.
+func keyNamespace(key string) (string, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(key, todoKeyPrefix), ":", 3)
+	if len(parts) < 3 || !strings.HasPrefix(parts[1], "v") {
+		return "", false
+	}
+	return parts[0] + ":" + parts[1], true
+}
+
+type CacheWarmer interface {
+	// WarmUp caches up to pages pages of the default todo list, with their todos, and returns how
+	// many todos it loaded.
+	WarmUp(ctx context.Context, pages int) (int, error)
+}
+
+type cacheWarmerImpl struct {
+	service TodoService
+}
+
+// NewCacheWarmer preloads through service, so the pages are cached exactly as the first requests
+// for them would cache them.
+func NewCacheWarmer(service TodoService) CacheWarmer {
+	return &cacheWarmerImpl{service: service}
+}
+
+func (w *cacheWarmerImpl) WarmUp(ctx context.Context, pages int) (int, error) {
+	warmed := 0
+	query := dto.TodoQuery{}
+	for range pages {
+		page, err := w.service.FindAll(ctx, query)
+		if err != nil {
+			return warmed, err
+		}
+		warmed += len(page.Todos)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	return warmed, nil
+}
@@ -0,0 +1,37 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/serialization"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCacheNamespacesCollectGarbage(t *testing.T) {
+	// Arrange
+	todoCache := cache.NewRedisCacheMock()
+	todoCache.On("Scan", mock.Anything, "todos:live:").Return([]string{"todos:live:msgpack:v1"}, nil)
+	todoCache.On("Scan", mock.Anything, "todos:").Return([]string{
+		"todos:live:msgpack:v1",
+		"todos:json:v2:item:a",
+		"todos:msgpack:v1:item:a",
+		"todos:json:v1:item:a",
+		"todos:json:v1:gen",
+		"todos:item:a",
+	}, nil)
+	todoCache.On("Del", mock.Anything, []string{"todos:json:v1:item:a", "todos:json:v1:gen", "todos:item:a"}).Return(nil)
+
+	namespaces := service.NewCacheNamespaces(todoCache, serialization.NewJSONCodec(), "2")
+
+	// Act
+	deleted, err := namespaces.CollectGarbage(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+	todoCache.AssertExpectations(t)
+}
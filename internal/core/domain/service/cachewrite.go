@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+)
+
+const (
+	// CacheWriteInvalidate deletes the written todo from the cache, suiting write-heavy installs
+	// where most written todos are not read again before they change. A read racing the write can
+	// cache the previous version until the item TTL expires it.
+	CacheWriteInvalidate = "invalidate"
+	// CacheWriteThrough stores the written todo in the cache right after the repository commits,
+	// suiting read-heavy installs.
+	CacheWriteThrough = "write-through"
+	// CacheWriteBehind acknowledges a write once it is in the write buffer and the cache, and
+	// flushes it to the repository later. List pages show it once it is flushed.
+	CacheWriteBehind = "write-behind"
+)
+
+// CacheWrite selects how writes reach the cache and the repository. Buffer is used, and required,
+// by CacheWriteBehind only. The zero value writes through.
+type CacheWrite struct {
+	Strategy string
+	Buffer   cache.WriteBuffer
+}
+
+// todoChange describes one write to a todo both as a repository operation and as a state
+// transition, so each write strategy can apply it its own way.
+type todoChange struct {
+	id     string
+	action string
+	create bool
+	// apply writes the change inside a transaction, returning the todo before (nil for a create)
+	// and after it.
+	apply func(ctx context.Context) (*dto.Todo, dto.Todo, error)
+	// project computes the todo after the change from the current one (nil for a create).
+	project func(current *dto.Todo, now time.Time) (dto.Todo, error)
+	// event describes the change for the outbox; a nil event emits nothing.
+	event func(before *dto.Todo, after dto.Todo) dto.TodoEvent
+	// cascade, when set, returns the changes to write before this one, reading todos through
+	// current: the repository inside the change's transaction, or the latest accepted write.
+	cascade func(ctx context.Context, current func(context.Context, string) (dto.Todo, error)) ([]todoChange, error)
+}
+
+type writeStrategy interface {
+	write(ctx context.Context, change todoChange) (dto.Todo, error)
+	// purged runs after todos were purged from the repository, which every strategy does directly.
+	purged(ctx context.Context, todos []dto.Todo)
+}
+
+func newWriteStrategy(s *todoServiceImpl, write CacheWrite) writeStrategy {
+	switch write.Strategy {
+	case CacheWriteInvalidate:
+		return &invalidateStrategy{service: s}
+	case CacheWriteBehind:
+		return &writeBehindStrategy{service: s, buffer: write.Buffer, through: &writeThroughStrategy{service: s}}
+	default:
+		return &writeThroughStrategy{service: s}
+	}
+}
+
+type invalidateStrategy struct {
+	service *todoServiceImpl
+}
+
+func (w *invalidateStrategy) write(ctx context.Context, change todoChange) (dto.Todo, error) {
+	written, err := w.service.commit(ctx, change)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	w.service.evict(ctx, written...)
+	return written[len(written)-1], nil
+}
+
+func (w *invalidateStrategy) purged(ctx context.Context, todos []dto.Todo) {
+	w.service.evict(ctx, todos...)
+}
+
+type writeThroughStrategy struct {
+	service *todoServiceImpl
+}
+
+func (w *writeThroughStrategy) write(ctx context.Context, change todoChange) (dto.Todo, error) {
+	written, err := w.service.commit(ctx, change)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	w.service.refresh(ctx, written...)
+	return written[len(written)-1], nil
+}
+
+func (w *writeThroughStrategy) purged(ctx context.Context, todos []dto.Todo) {
+	tombstones := make([]dto.Todo, 0, len(todos))
+	for _, todo := range todos {
+		tombstones = append(tombstones, tombstone(todo))
+	}
+	w.service.refresh(ctx, tombstones...)
+}
+
+// writeBehindStrategy projects each write from the latest cached todo, caches the result without a
+// TTL until the flusher has stored it and appends it to the buffer. When the cache or buffer cannot
+// be used the write goes through to the repository instead. Two instances writing the same
+// todo at once can both project from one version; the first flushed wins and the flusher then
+// caches what the repository holds.
+type writeBehindStrategy struct {
+	service *todoServiceImpl
+	buffer  cache.WriteBuffer
+	through *writeThroughStrategy
+}
+
+func (w *writeBehindStrategy) write(ctx context.Context, change todoChange) (dto.Todo, error) {
+	changes := []todoChange{change}
+	if change.cascade != nil {
+		cascaded, err := change.cascade(ctx, w.latest)
+		if errors.Is(err, errCacheUnavailable) {
+			return w.through.write(ctx, change)
+		}
+		if err != nil {
+			return dto.Todo{}, err
+		}
+		changes = append(cascaded, change)
+	}
+
+	var after dto.Todo
+	for _, change := range changes {
+		var err error
+		if after, err = w.accept(ctx, change); err != nil {
+			return dto.Todo{}, err
+		}
+	}
+	return after, nil
+}
+
+// accept buffers one change, or writes it through when the cache or buffer cannot be used.
+func (w *writeBehindStrategy) accept(ctx context.Context, change todoChange) (dto.Todo, error) {
+	s := w.service
+	var current *dto.Todo
+	if !change.create {
+		latest, err := w.latest(ctx, change.id)
+		if errors.Is(err, errCacheUnavailable) {
+			return w.through.write(ctx, change)
+		}
+		if err != nil {
+			return dto.Todo{}, err
+		}
+		current = &latest
+	}
+
+	after, err := change.project(current, time.Now())
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	write := dto.BufferedWrite{Todo: after, History: s.historyEntry(ctx, change.action, after.Id, current, &after)}
+	if change.event != nil {
+		if event := change.event(current, after); event != nil {
+			outboxEvent, err := dto.NewOutboxEvent(event, time.Now())
+			if err != nil {
+				return dto.Todo{}, err
+			}
+			write.Event = &outboxEvent
+		}
+	}
+
+	// Later writes project from the cached todo, so a write is only buffered once it is cached.
+	key := s.keys.item(after.Id)
+	value, err := encodeTodo(s.codec, after)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	if err := s.cache.SetIfNewer(ctx, map[string]string{key: value}, 0); err != nil {
+		cacheFailed(ctx, "set", key, err)
+		return w.through.write(ctx, change)
+	}
+	if err := w.buffer.Append(ctx, write); err != nil {
+		cacheFailed(ctx, "buffer", key, err)
+		// The cached todo will never be flushed, so it is dropped before the write goes through.
+		if err := s.cache.Del(ctx, key); err != nil {
+			cacheFailed(ctx, "delete", key, err)
+		}
+		return w.through.write(ctx, change)
+	}
+	s.expireLists(ctx)
+	return after, nil
+}
+
+func (w *writeBehindStrategy) purged(ctx context.Context, todos []dto.Todo) {
+	w.through.purged(ctx, todos)
+}
+
+var errCacheUnavailable = errors.New("cache unavailable")
+
+// latest returns the todo as the last accepted write left it, trashed or not. The cache holds
+// writes not flushed yet, so it is read before the repository; if it cannot be read the result
+// could miss one of them and errCacheUnavailable is returned.
+func (w *writeBehindStrategy) latest(ctx context.Context, id string) (dto.Todo, error) {
+	s := w.service
+	key := s.keys.item(id)
+	cached, err := s.cache.Get(ctx, key)
+	switch {
+	case err == nil:
+		todo, err := decodeTodo(s.codec, cached)
+		if err == nil {
+			if todo.Version == math.MaxInt64 {
+				return dto.Todo{}, dto.ErrTodoNotFound
+			}
+			return todo, nil
+		}
+		s.dropCorrupted(ctx, key, err)
+	case !errors.Is(err, cache.ErrCacheMiss):
+		cacheFailed(ctx, "get", key, err)
+		return dto.Todo{}, errCacheUnavailable
+	}
+	return s.repo.FindByIdForUpdate(ctx, id)
+}
+
+// activeVersion rejects a write to a trashed todo or, when expectedVersion is set, to another version.
+func activeVersion(current *dto.Todo, expectedVersion int64) error {
+	if current.DeletedAt.Valid {
+		return dto.ErrTodoNotFound
+	}
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		return dto.ErrVersionConflict
+	}
+	return nil
+}
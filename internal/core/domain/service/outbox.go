@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/event"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+type OutboxRelay interface {
+	Relay(ctx context.Context, batchSize int) (int, error)
+}
+
+type outboxRelayImpl struct {
+	outbox      repository.OutboxRepository
+	transactor  repository.Transactor
+	publisher   event.Publisher
+	maxAttempts int
+}
+
+// NewOutboxRelay returns a relay that gives up on an event after maxAttempts failed publishes, or
+// keeps retrying it when maxAttempts is not positive.
+func NewOutboxRelay(outbox repository.OutboxRepository, transactor repository.Transactor, publisher event.Publisher, maxAttempts int) OutboxRelay {
+	return &outboxRelayImpl{
+		outbox:      outbox,
+		transactor:  transactor,
+		publisher:   publisher,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Relay publishes up to batchSize pending events in order. It stops at the first publish failure so
+// later events are not delivered ahead of it, unless that failure was the event's last attempt: the
+// event is then left as a dead letter and the batch goes on without it. An event published but not
+// yet marked is sent again on the next run, which is why delivery is at-least-once.
+//
+// The order only holds within one relay. Relays on other replicas skip the rows this one has
+// claimed and publish the events after them concurrently.
+func (r *outboxRelayImpl) Relay(ctx context.Context, batchSize int) (int, error) {
+	var published []string
+	var publishErr error
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		events, err := r.outbox.FindUnpublished(ctx, batchSize, r.maxAttempts)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if publishErr = r.publisher.Publish(ctx, event); publishErr != nil {
+				if err := r.outbox.MarkFailed(ctx, event.Id); err != nil {
+					return err
+				}
+				if r.maxAttempts <= 0 || event.Attempts+1 < r.maxAttempts {
+					break
+				}
+				logger.Log.Error("Giving up on todo event, leaving it as a dead letter.",
+					zap.String("event_id", event.Id), zap.String("event_type", event.EventType), zap.Error(publishErr))
+				publishErr = nil
+				continue
+			}
+			published = append(published, event.Id)
+		}
+
+		return r.outbox.MarkPublished(ctx, published, time.Now())
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(published), publishErr
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/event"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOutboxRelay(t *testing.T) {
+	events := []dto.OutboxEvent{
+		{Id: "e1", EventType: dto.EventTodoCreated, AggregateId: "1"},
+		{Id: "e2", EventType: dto.EventTodoStatusChanged, AggregateId: "1", Attempts: 1},
+		{Id: "e3", EventType: dto.EventTodoDeleted, AggregateId: "1"},
+	}
+
+	testCases := []struct {
+		description       string
+		publishErr        error
+		maxAttempts       int
+		expectedPublished []string
+		expectedErr       error
+	}{
+		{
+			description:       "Relay publishes every pending event.",
+			expectedPublished: []string{"e1", "e2", "e3"},
+		},
+		{
+			description:       "Relay stops at the first failed event.",
+			publishErr:        errors.New("broker unavailable"),
+			expectedPublished: []string{"e1"},
+			expectedErr:       errors.New("broker unavailable"),
+		},
+		{
+			description:       "Relay stops at a failed event with attempts left.",
+			publishErr:        errors.New("broker unavailable"),
+			maxAttempts:       3,
+			expectedPublished: []string{"e1"},
+			expectedErr:       errors.New("broker unavailable"),
+		},
+		{
+			description:       "Relay goes past an event failing its last attempt.",
+			publishErr:        errors.New("payload rejected"),
+			maxAttempts:       2,
+			expectedPublished: []string{"e1", "e3"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			outbox := repository.NewOutboxRepositoryMock()
+			publisher := event.NewPublisherMock()
+
+			outbox.On("FindUnpublished", mock.Anything, 10, testCase.maxAttempts).Return(events, nil)
+			publisher.On("Publish", mock.Anything, events[0]).Return(nil)
+			if testCase.publishErr != nil {
+				publisher.On("Publish", mock.Anything, events[1]).Return(testCase.publishErr)
+				outbox.On("MarkFailed", mock.Anything, "e2").Return(nil)
+			} else {
+				publisher.On("Publish", mock.Anything, events[1]).Return(nil)
+			}
+			if testCase.expectedErr == nil {
+				publisher.On("Publish", mock.Anything, events[2]).Return(nil)
+			}
+			outbox.On("MarkPublished", mock.Anything, testCase.expectedPublished, mock.Anything).Return(nil)
+
+			relay := service.NewOutboxRelay(outbox, repository.NewTransactorMock(), publisher, testCase.maxAttempts)
+
+			// Act
+			published, err := relay.Relay(context.Background(), 10)
+
+			// Assert
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, len(testCase.expectedPublished), published)
+
+			outbox.AssertExpectations(t)
+			publisher.AssertExpectations(t)
+		})
+	}
+}
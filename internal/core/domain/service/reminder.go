@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/notify"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+)
+
+type ReminderDispatcher interface {
+	Dispatch(ctx context.Context, batchSize int) (int, error)
+}
+
+type reminderDispatcherImpl struct {
+	repo       repository.TodoRepository
+	transactor repository.Transactor
+	notifier   notify.Notifier
+}
+
+func NewReminderDispatcher(repo repository.TodoRepository, transactor repository.Transactor, notifier notify.Notifier) ReminderDispatcher {
+	return &reminderDispatcherImpl{
+		repo:       repo,
+		transactor: transactor,
+		notifier:   notifier,
+	}
+}
+
+// Dispatch takes up to batchSize todos with a reminder due, moves each on to its next reminder and
+// returns how many it took. The notifier is only called once that is committed, so no row stays
+// locked while it runs. Like the outbox relay it stops at the first notifier failure, handing that
+// reminder and the ones after it back to the next run; a reminder is only lost if the process stops
+// between the commit and its notification.
+func (d *reminderDispatcherImpl) Dispatch(ctx context.Context, batchSize int) (int, error) {
+	now := time.Now()
+	var todos []dto.Todo
+	err := d.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		todos, err = d.repo.FindRemindersDue(ctx, now, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, todo := range todos {
+			if err := d.repo.SetNextReminder(ctx, todo.Id, todo.NextReminder(now)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for index, todo := range todos {
+		// A todo rescheduled since its reminder was planned may have nothing due; it only moves on.
+		offset, remindAt, ok := todo.ReminderDue(now)
+		if !ok {
+			continue
+		}
+		notifyErr := d.notifier.Notify(ctx, dto.TodoReminderDue{
+			TodoId:   todo.Id,
+			Topic:    todo.Topic,
+			Due:      todo.Due,
+			Offset:   dto.ReminderOffsets{offset}.String(),
+			RemindAt: remindAt.In(time.Local),
+		})
+		if notifyErr != nil {
+			if err := d.handBack(ctx, todos[index:]); err != nil {
+				return len(todos), errors.Join(notifyErr, err)
+			}
+			return len(todos), notifyErr
+		}
+	}
+
+	return len(todos), nil
+}
+
+// handBack puts back the reminders todos had before Dispatch moved them on, so the next run finds
+// them due again. A todo rescheduled in between is only moved on again by that run.
+func (d *reminderDispatcherImpl) handBack(ctx context.Context, todos []dto.Todo) error {
+	return d.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, todo := range todos {
+			if err := d.repo.SetNextReminder(ctx, todo.Id, todo.NextReminderAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
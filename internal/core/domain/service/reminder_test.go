@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/notify"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReminderDispatcher(t *testing.T) {
+	due := time.Now().Add(time.Hour).Truncate(time.Second)
+	planned := due.Add(-24 * time.Hour)
+	todo := dto.Todo{
+		Id:             "1",
+		Topic:          "Pay rent",
+		Status:         dto.TodoStatusPending,
+		Due:            dto.DueDate{At: &due},
+		Reminders:      dto.ReminderOffsets{24 * time.Hour, 2 * time.Hour, 30 * time.Minute},
+		NextReminderAt: &planned,
+	}
+	next := due.Add(-30 * time.Minute)
+
+	testCases := []struct {
+		description string
+		notifyErr   error
+		expectedErr error
+	}{
+		{
+			description: "Dispatch sends the latest reminder due and moves on to the next one.",
+		},
+		{
+			description: "Dispatch hands the reminder back to the next run when the notifier fails.",
+			notifyErr:   errors.New("smtp unavailable"),
+			expectedErr: errors.New("smtp unavailable"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			repo := repository.NewTodoRepositoryMock()
+			notifier := notify.NewNotifierMock()
+			transactor := &trackingTransactor{}
+
+			repo.On("FindRemindersDue", mock.Anything, mock.Anything, 10).Return([]dto.Todo{todo}, nil)
+			repo.On("SetNextReminder", mock.Anything, "1", &next).Return(nil).Once()
+			// The 24h and 2h reminders were both missed, so only the 2h one is sent.
+			notifier.On("Notify", mock.Anything, mock.MatchedBy(func(reminder dto.TodoReminderDue) bool {
+				return reminder.TodoId == "1" && reminder.Offset == "2h" && reminder.RemindAt.Equal(due.Add(-2*time.Hour))
+			})).Return(testCase.notifyErr).Run(func(args mock.Arguments) {
+				assert.False(t, transactor.active, "notified inside the transaction")
+			})
+			if testCase.notifyErr != nil {
+				repo.On("SetNextReminder", mock.Anything, "1", &planned).Return(nil).Once()
+			}
+
+			dispatcher := service.NewReminderDispatcher(repo, transactor, notifier)
+
+			// Act
+			taken, err := dispatcher.Dispatch(context.Background(), 10)
+
+			// Assert
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, 1, taken)
+
+			repo.AssertExpectations(t)
+			notifier.AssertExpectations(t)
+		})
+	}
+}
+
+// trackingTransactor runs fn directly, like the transactor mock, and records whether it is inside it.
+type trackingTransactor struct {
+	active bool
+}
+
+func (t *trackingTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.active = true
+	defer func() { t.active = false }()
+	return fn(ctx)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+)
+
+const (
+	// SubtaskReject fails completing or deleting a todo with open subtasks, naming them.
+	SubtaskReject = "reject"
+	// SubtaskCascade completes or trashes the open subtasks first, deepest first, each as its own
+	// write with its own history entry and event, in the parent's transaction. Under write-behind
+	// the writes are buffered one by one, so a failure part way leaves the subtasks written so far;
+	// retrying the parent finishes the rest.
+	SubtaskCascade = "cascade"
+)
+
+// SubtaskPolicy sets what completing or deleting a todo does to subtasks below it that are not done,
+// or not trashed when deleting. The zero value rejects both.
+type SubtaskPolicy struct {
+	Complete string
+	Delete   string
+}
+
+// Children lists the live subtasks directly below a todo, or its whole subtree level by level when
+// recursive is set, each with its progress.
+func (s *todoServiceImpl) Children(ctx context.Context, id string, recursive bool) ([]dto.Todo, error) {
+	if _, err := s.findById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var children []dto.Todo
+	var err error
+	if recursive {
+		children, err = s.repo.FindSubtree(ctx, id)
+	} else {
+		children, err = s.repo.FindChildren(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.withProgress(ctx, children...)
+}
+
+// withProgress sets the progress of every todo that has subtasks. Progress is read from the
+// repository each time, as any write below a todo changes it.
+func (s *todoServiceImpl) withProgress(ctx context.Context, todos ...dto.Todo) ([]dto.Todo, error) {
+	if len(todos) == 0 {
+		return todos, nil
+	}
+	ids := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, todo.Id)
+	}
+	progress, err := s.repo.Progress(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for index := range todos {
+		if p, ok := progress[todos[index].Id]; ok {
+			todos[index].Progress = &p
+		}
+	}
+	return todos, nil
+}
+
+// checkParent rejects todo, as it is about to be stored, when its parent is missing, trashed, or
+// done while todo is not. Create, status updates and restores all keep to this.
+func (s *todoServiceImpl) checkParent(ctx context.Context, todo dto.Todo) error {
+	if todo.ParentId == nil {
+		return nil
+	}
+	parent, err := s.findById(ctx, *todo.ParentId)
+	if errors.Is(err, dto.ErrTodoNotFound) {
+		return dto.ErrInvalidParent
+	}
+	if err != nil {
+		return err
+	}
+	if parent.Status == dto.TodoStatusDone && todo.Status != dto.TodoStatusDone {
+		return dto.ErrInvalidParent
+	}
+	return nil
+}
+
+// checkStatus rejects giving todo status while its parent is done. Only a status other than done
+// can break the parent rule, so completing a todo looks nothing up.
+func (s *todoServiceImpl) checkStatus(ctx context.Context, todo dto.Todo, status dto.TodoStatus) error {
+	if status == dto.TodoStatusDone {
+		return nil
+	}
+	todo.Status = status
+	return s.checkParent(ctx, todo)
+}
+
+// cascadeSubtasks returns the cascade that applies policy to the subtasks below id that open
+// reports, before id itself is completed or deleted: reject fails with an *OpenSubtasksError and
+// cascade returns the change settle makes to each, deepest first. Subtasks are read through current
+// so writes not stored yet are seen, and when any is open the todo and its version are checked
+// first so a stale write cascades nothing.
+func (s *todoServiceImpl) cascadeSubtasks(id string, expectedVersion int64, policy string, open func(dto.Todo) bool, settle func(dto.Todo) todoChange) func(context.Context, func(context.Context, string) (dto.Todo, error)) ([]todoChange, error) {
+	return func(ctx context.Context, current func(context.Context, string) (dto.Todo, error)) ([]todoChange, error) {
+		subtree, err := s.repo.FindSubtree(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		var pending []dto.Todo
+		for _, subtask := range subtree {
+			latest, err := current(ctx, subtask.Id)
+			if errors.Is(err, dto.ErrTodoNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !latest.DeletedAt.Valid && open(latest) {
+				pending = append(pending, latest)
+			}
+		}
+		if len(pending) == 0 {
+			return nil, nil
+		}
+		todo, err := current(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := activeVersion(&todo, expectedVersion); err != nil {
+			return nil, err
+		}
+
+		if policy != SubtaskCascade {
+			ids := make([]string, 0, len(pending))
+			for _, subtask := range pending {
+				ids = append(ids, subtask.Id)
+			}
+			return nil, &dto.OpenSubtasksError{TodoId: id, Open: ids}
+		}
+		changes := make([]todoChange, 0, len(pending))
+		for _, subtask := range slices.Backward(pending) {
+			changes = append(changes, settle(subtask))
+		}
+		return changes, nil
+	}
+}
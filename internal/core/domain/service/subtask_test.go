@@ -0,0 +1,283 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/memory"
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/serialization"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTodoserviceCompleteWithOpenSubtasks(t *testing.T) {
+	parentId, childId := "1", "2"
+	parent := dto.Todo{Id: "1", Status: dto.TodoStatusInProgress, Version: 1}
+	// The subtree comes level by level: 2 below 1, then 3 below 2. 4 is done already.
+	subtree := []dto.Todo{
+		{Id: "2", ParentId: &parentId, Status: dto.TodoStatusPending, Version: 1},
+		{Id: "4", ParentId: &parentId, Status: dto.TodoStatusDone, Version: 1},
+		{Id: "3", ParentId: &childId, Status: dto.TodoStatusReopened, Version: 1},
+	}
+
+	testCases := []struct {
+		description string
+		policy      string
+		expectedErr error
+	}{
+		{
+			description: "Reject names the open subtasks and writes nothing.",
+			policy:      service.SubtaskReject,
+			expectedErr: &dto.OpenSubtasksError{TodoId: "1", Open: []string{"2", "3"}},
+		},
+		{
+			description: "Cascade completes the open subtasks deepest first, then the todo.",
+			policy:      service.SubtaskCascade,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			todoRepo := repository.NewTodoRepositoryMock()
+			todoCache := cache.NewRedisCacheMock()
+			todoHistory := repository.NewTodoHistoryRepositoryMock()
+			todoOutbox := repository.NewOutboxRepositoryMock()
+
+			// The subtree and the todo are read in the write's transaction.
+			transactor := &trackingTransactor{}
+			inTransaction := func(args mock.Arguments) {
+				assert.True(t, transactor.active, "read outside the transaction")
+			}
+			todoRepo.On("FindSubtree", mock.Anything, "1").Return(subtree, nil).Run(inTransaction)
+			for _, todo := range append([]dto.Todo{parent}, subtree...) {
+				todoRepo.On("FindByIdForUpdate", mock.Anything, todo.Id).Return(todo, nil).Run(inTransaction)
+			}
+
+			var completed []string
+			if testCase.policy == service.SubtaskCascade {
+				for _, todo := range []dto.Todo{subtree[2], subtree[0], parent} {
+					input := dto.TodoInputUpdateStatus{Id: todo.Id, Status: dto.TodoStatusDone}
+					done := todo
+					done.Status, done.Version = dto.TodoStatusDone, 2
+					todoRepo.On("Update", mock.Anything, input).Return(done, nil).Run(func(args mock.Arguments) {
+						completed = append(completed, todo.Id)
+					})
+					todoCache.On("SetIfNewer", mock.Anything, cachedItem(todo.Id), mock.Anything).Return(nil)
+				}
+				todoHistory.On("Append", mock.Anything, mock.Anything).Return(nil)
+				todoOutbox.On("Append", mock.Anything, mock.Anything).Return(nil)
+				todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, transactor, todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{Complete: testCase.policy})
+
+			// Act
+			_, err := todoService.Update(context.Background(), dto.TodoInputUpdateStatus{Id: "1", Status: dto.TodoStatusDone})
+
+			// Assert
+			assert.Equal(t, testCase.expectedErr, err)
+			if testCase.policy == service.SubtaskCascade {
+				assert.Equal(t, []string{"3", "2", "1"}, completed)
+			}
+			todoRepo.AssertExpectations(t)
+			todoHistory.AssertExpectations(t)
+			todoOutbox.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTodoserviceParentRule(t *testing.T) {
+	reopen := func(ctx context.Context, todoService service.TodoService) error {
+		_, err := todoService.Update(ctx, dto.TodoInputUpdateStatus{Id: "c", Status: dto.TodoStatusReopened})
+		return err
+	}
+	restore := func(ctx context.Context, todoService service.TodoService) error {
+		_, err := todoService.Restore(ctx, "c")
+		return err
+	}
+
+	testCases := []struct {
+		description string
+		arrange     []func(ctx context.Context, todoService service.TodoService) error
+		act         func(ctx context.Context, todoService service.TodoService) error
+		expectedErr error
+	}{
+		{
+			description: "Reopening a subtask below a done parent is rejected.",
+			arrange:     []func(context.Context, service.TodoService) error{complete("c"), complete("p")},
+			act:         reopen,
+			expectedErr: dto.ErrInvalidParent,
+		},
+		{
+			description: "Reopening a subtask below a live parent is allowed.",
+			arrange:     []func(context.Context, service.TodoService) error{complete("c")},
+			act:         reopen,
+		},
+		{
+			description: "Restoring a subtask below a trashed parent is rejected.",
+			arrange:     []func(context.Context, service.TodoService) error{trash("c"), trash("p")},
+			act:         restore,
+			expectedErr: dto.ErrInvalidParent,
+		},
+		{
+			description: "Restoring an open subtask below a done parent is rejected.",
+			arrange:     []func(context.Context, service.TodoService) error{trash("c"), complete("p")},
+			act:         restore,
+			expectedErr: dto.ErrInvalidParent,
+		},
+		{
+			description: "Restoring a subtask below a live parent is allowed.",
+			arrange:     []func(context.Context, service.TodoService) error{trash("c")},
+			act:         restore,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			todoService := service.NewTodoService(memory.NewMemoryTodoRepository(), memory.NewMemoryTodoHistoryRepository(), memory.NewMemoryOutboxRepository(), memory.NewMemoryTransactor(), memory.NewMemoryCache(), serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+			parentId := "p"
+			for _, todo := range []dto.Todo{
+				{Id: "p", Status: dto.TodoStatusPending, Version: 1},
+				{Id: "c", ParentId: &parentId, Status: dto.TodoStatusPending, Version: 1},
+			} {
+				_, err := todoService.Create(ctx, todo)
+				assert.NoError(t, err)
+			}
+			for _, step := range testCase.arrange {
+				assert.NoError(t, step(ctx, todoService))
+			}
+
+			// Act
+			err := testCase.act(ctx, todoService)
+
+			// Assert
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
+
+func TestTodoserviceSubtasksSeeBufferedWrites(t *testing.T) {
+	testCases := []struct {
+		description string
+		policy      service.SubtaskPolicy
+		buffered    []func(context.Context, service.TodoService) error
+		act         func(context.Context, service.TodoService) error
+		expected    map[string]dto.TodoStatus
+	}{
+		{
+			description: "Completing waits for no subtask completed in the buffer.",
+			buffered:    []func(context.Context, service.TodoService) error{complete("c1"), complete("c2")},
+			act:         complete("p"),
+			expected:    map[string]dto.TodoStatus{"p": dto.TodoStatusDone, "c1": dto.TodoStatusDone, "c2": dto.TodoStatusDone},
+		},
+		{
+			description: "Cascade only completes the subtasks still open once buffered writes count.",
+			policy:      service.SubtaskPolicy{Complete: service.SubtaskCascade},
+			buffered:    []func(context.Context, service.TodoService) error{complete("c1")},
+			act:         complete("p"),
+			expected:    map[string]dto.TodoStatus{"p": dto.TodoStatusDone, "c1": dto.TodoStatusDone, "c2": dto.TodoStatusDone},
+		},
+		{
+			description: "Deleting waits for no subtask trashed in the buffer.",
+			buffered:    []func(context.Context, service.TodoService) error{trash("c1"), trash("c2")},
+			act:         trash("p"),
+			expected:    map[string]dto.TodoStatus{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			todoRepo := memory.NewMemoryTodoRepository()
+			todoHistory := memory.NewMemoryTodoHistoryRepository()
+			todoOutbox := memory.NewMemoryOutboxRepository()
+			transactor := memory.NewMemoryTransactor()
+			todoCache := memory.NewMemoryCache()
+			buffer := memory.NewMemoryWriteBuffer(time.Minute)
+			todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, transactor, todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{Strategy: service.CacheWriteBehind, Buffer: buffer}, testCase.policy)
+			flusher := service.NewWriteBehindFlusher(todoRepo, todoHistory, todoOutbox, transactor, buffer, todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{})
+
+			parentId := "p"
+			for _, todo := range []dto.Todo{
+				{Id: "p", Status: dto.TodoStatusPending},
+				{Id: "c1", ParentId: &parentId, Status: dto.TodoStatusPending},
+				{Id: "c2", ParentId: &parentId, Status: dto.TodoStatusPending},
+			} {
+				_, err := todoService.Create(ctx, todo)
+				assert.NoError(t, err)
+			}
+			_, err := flusher.Flush(ctx, 10)
+			assert.NoError(t, err)
+			// These writes stay in the buffer, so the repository still has the subtasks open.
+			for _, step := range testCase.buffered {
+				assert.NoError(t, step(ctx, todoService))
+			}
+
+			// Act
+			err = testCase.act(ctx, todoService)
+
+			// Assert
+			assert.NoError(t, err)
+			_, err = flusher.Flush(ctx, 10)
+			assert.NoError(t, err)
+			page, err := todoService.FindAll(ctx, dto.TodoQuery{})
+			assert.NoError(t, err)
+			statuses := map[string]dto.TodoStatus{}
+			for _, todo := range page.Todos {
+				statuses[todo.Id] = todo.Status
+			}
+			assert.Equal(t, testCase.expected, statuses)
+		})
+	}
+}
+
+func complete(id string) func(context.Context, service.TodoService) error {
+	return func(ctx context.Context, todoService service.TodoService) error {
+		_, err := todoService.Update(ctx, dto.TodoInputUpdateStatus{Id: id, Status: dto.TodoStatusDone})
+		return err
+	}
+}
+
+func trash(id string) func(context.Context, service.TodoService) error {
+	return func(ctx context.Context, todoService service.TodoService) error {
+		return todoService.Delete(ctx, dto.TodoInputDelete{Id: id})
+	}
+}
+
+func TestTodoserviceFindAllProgress(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	todoService := service.NewTodoService(memory.NewMemoryTodoRepository(), memory.NewMemoryTodoHistoryRepository(), memory.NewMemoryOutboxRepository(), memory.NewMemoryTransactor(), memory.NewMemoryCache(), serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+	parentId := "p"
+	for _, todo := range []dto.Todo{
+		{Id: "p", Status: dto.TodoStatusPending, Version: 1},
+		{Id: "c1", ParentId: &parentId, Status: dto.TodoStatusPending, Version: 1},
+		{Id: "c2", ParentId: &parentId, Status: dto.TodoStatusDone, Version: 1},
+	} {
+		_, err := todoService.Create(ctx, todo)
+		assert.NoError(t, err)
+	}
+
+	// The first call loads the page and the second serves it from the cache.
+	for range 2 {
+		// Act
+		page, err := todoService.FindAll(ctx, dto.TodoQuery{})
+
+		// Assert
+		assert.NoError(t, err)
+		progress := map[string]*dto.TodoProgress{}
+		for _, todo := range page.Todos {
+			progress[todo.Id] = todo.Progress
+		}
+		assert.Equal(t, map[string]*dto.TodoProgress{"p": {Done: 1, Total: 2, Percent: 50}, "c1": nil, "c2": nil}, progress)
+	}
+}
@@ -2,170 +2,871 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/requestctx"
 	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
 	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
 )
 
+const (
+	defaultListTTL      = 5 * time.Minute
+	defaultListFreshTTL = time.Minute
+	defaultItemTTL      = 5 * time.Minute
+	// listLoadTimeout bounds a shared list load, which no single caller's context can cancel.
+	listLoadTimeout = 30 * time.Second
+)
+
+// CacheTTL sets how long list pages and todo items stay cached. A list page older than ListFresh
+// is still served but refreshed in the background until List expires it. Zero fields use the defaults.
+type CacheTTL struct {
+	List      time.Duration
+	ListFresh time.Duration
+	Item      time.Duration
+}
+
 type TodoService interface {
-	FindAll() ([]dto.Todo, error)
-	Create(dto.Todo) error
-	Update(dto.TodoInputUpdateStatus) error
-	Delete(dto.TodoInputDelete) error
+	FindAll(ctx context.Context, query dto.TodoQuery) (dto.TodoPage, error)
+	FindById(ctx context.Context, id string) (dto.Todo, error)
+	Children(ctx context.Context, id string, recursive bool) ([]dto.Todo, error)
+	Search(ctx context.Context, query dto.TodoSearchQuery) ([]dto.TodoSearchResult, error)
+	Create(ctx context.Context, input dto.Todo) (dto.Todo, error)
+	Update(ctx context.Context, input dto.TodoInputUpdateStatus) (dto.Todo, error)
+	Schedule(ctx context.Context, input dto.TodoInputSchedule) (dto.Todo, error)
+	Move(ctx context.Context, input dto.TodoInputMove) (dto.Todo, error)
+	Overdue(ctx context.Context, limit int) ([]dto.Todo, error)
+	DueWithin(ctx context.Context, within time.Duration, limit int) ([]dto.Todo, error)
+	Delete(ctx context.Context, input dto.TodoInputDelete) error
+	FindTrash(ctx context.Context) ([]dto.Todo, error)
+	Restore(ctx context.Context, id string) (dto.Todo, error)
+	Purge(ctx context.Context, id string) error
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int, error)
+	History(ctx context.Context, id string) ([]dto.TodoHistory, error)
 }
 
 type todoServiceImpl struct {
-	repo  repository.TodoRepository
-	cache cache.Cache
+	repo       repository.TodoRepository
+	history    repository.TodoHistoryRepository
+	outbox     repository.OutboxRepository
+	transactor repository.Transactor
+	cache      cache.Cache
+	codec      codec.Codec
+	keys       todoKeys
+	ttl        CacheTTL
+	writes     writeStrategy
+	subtasks   SubtaskPolicy
+	loads      singleflight.Group
+	// positions holds the last position nextPosition handed out.
+	positions struct {
+		sync.Mutex
+		last string
+	}
 }
 
-func NewTodoService(repo repository.TodoRepository, cache cache.Cache) TodoService {
-	return &todoServiceImpl{
-		repo:  repo,
-		cache: cache,
+func NewTodoService(repo repository.TodoRepository, history repository.TodoHistoryRepository, outbox repository.OutboxRepository, transactor repository.Transactor, cache cache.Cache, codec codec.Codec, schema string, ttl CacheTTL, write CacheWrite, subtasks SubtaskPolicy) TodoService {
+	if ttl.List <= 0 {
+		ttl.List = defaultListTTL
+	}
+	if ttl.ListFresh <= 0 || ttl.ListFresh > ttl.List {
+		ttl.ListFresh = min(defaultListFreshTTL, ttl.List)
 	}
+	if ttl.Item <= 0 {
+		ttl.Item = defaultItemTTL
+	}
+	s := &todoServiceImpl{
+		repo:       repo,
+		history:    history,
+		outbox:     outbox,
+		transactor: transactor,
+		cache:      cache,
+		codec:      codec,
+		keys:       newTodoKeys(codec, schema),
+		ttl:        ttl,
+		subtasks:   subtasks,
+	}
+	s.writes = newWriteStrategy(s, write)
+	return s
 }
 
-func (s *todoServiceImpl) FindAll() ([]dto.Todo, error) {
-	cachedData, err := s.cache.Get(context.Background(), "todos")
+// FindAll returns a page of todos, each with its progress when it has subtasks. Cached pages leave
+// progress out; it is read for the whole page in one query.
+func (s *todoServiceImpl) FindAll(ctx context.Context, query dto.TodoQuery) (dto.TodoPage, error) {
+	page, err := s.findPage(ctx, query)
+	if err != nil {
+		return dto.TodoPage{}, err
+	}
+	// The page may be shared with other callers of the same load, so progress goes on a copy.
+	if page.Todos, err = s.withProgress(ctx, slices.Clone(page.Todos)...); err != nil {
+		return dto.TodoPage{}, err
+	}
+	return page, nil
+}
 
+func (s *todoServiceImpl) findPage(ctx context.Context, query dto.TodoQuery) (dto.TodoPage, error) {
+	query, err := query.Normalize()
 	if err != nil {
-		todos, err := s.repo.FindAll()
+		return dto.TodoPage{}, err
+	}
+
+	key := s.listKey(ctx, query)
+	if page, fresh, ok := s.cachedPage(ctx, key); ok {
+		if !fresh {
+			// DoChan starts a refresh only when none is in flight for key; nobody waits on the result.
+			s.loads.DoChan(key, s.loadPage(ctx, key, query))
+		}
+		return page, nil
+	}
+
+	// A caller that gives up stops waiting, while the shared load goes on for the others.
+	select {
+	case result := <-s.loads.DoChan(key, s.loadPage(ctx, key, query)):
+		if result.Err != nil {
+			return dto.TodoPage{}, result.Err
+		}
+		return result.Val.(dto.TodoPage), nil
+	case <-ctx.Done():
+		return dto.TodoPage{}, ctx.Err()
+	}
+}
+
+// loadPage reads a page from the repository and caches it. Concurrent callers for the same key share
+// one load through s.loads, which runs detached from any single caller's cancellation and is
+// bounded by listLoadTimeout instead.
+func (s *todoServiceImpl) loadPage(ctx context.Context, key string, query dto.TodoQuery) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), listLoadTimeout)
+		defer cancel()
+
+		page, err := s.repo.FindAll(ctx, query)
 		if err != nil {
-			return nil, err
+			return dto.TodoPage{}, err
 		}
 
-		data, err := json.Marshal(todos)
+		s.cacheTodos(ctx, s.ttl.Item, page.Todos...)
+
+		index := dto.CachedTodoPage{
+			Ids:        make([]string, 0, len(page.Todos)),
+			NextCursor: page.NextCursor,
+			Total:      page.Total,
+			FreshUntil: time.Now().Add(s.ttl.ListFresh),
+		}
+		for _, todo := range page.Todos {
+			index.Ids = append(index.Ids, todo.Id)
+		}
+		data, err := s.codec.Marshal(index)
 		if err != nil {
-			return nil, err
+			return dto.TodoPage{}, err
 		}
 
-		if err := s.cache.Set(context.Background(), "todos", string(data), 0); err != nil {
-			return nil, err
+		if err := s.cache.Set(ctx, key, string(data), s.ttl.List); err != nil {
+			cacheFailed(ctx, "set", key, err)
 		}
 
-		return todos, nil
+		return page, nil
 	}
+}
 
-	var todos []dto.Todo
-	if err := json.Unmarshal([]byte(cachedData), &todos); err != nil {
+// FindById returns a todo with its progress when it has subtasks.
+func (s *todoServiceImpl) FindById(ctx context.Context, id string) (dto.Todo, error) {
+	todo, err := s.findById(ctx, id)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	todos, err := s.withProgress(ctx, todo)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	return todos[0], nil
+}
+
+func (s *todoServiceImpl) findById(ctx context.Context, id string) (dto.Todo, error) {
+	key := s.keys.item(id)
+	cached, err := s.cache.Get(ctx, key)
+	if err == nil {
+		todo, err := decodeTodo(s.codec, cached)
+		if err == nil {
+			if todo.DeletedAt.Valid {
+				return dto.Todo{}, dto.ErrTodoNotFound
+			}
+			return todo, nil
+		}
+		s.dropCorrupted(ctx, key, err)
+	} else {
+		cacheFailed(ctx, "get", key, err)
+	}
+
+	todo, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+
+	s.cacheTodos(ctx, s.ttl.Item, todo)
+	return todo, nil
+}
+
+func (s *todoServiceImpl) Search(ctx context.Context, query dto.TodoSearchQuery) ([]dto.TodoSearchResult, error) {
+	query, err := query.Normalize()
+	if err != nil {
 		return nil, err
 	}
+	return s.repo.Search(ctx, query)
+}
+
+// Create stores input at version 1 with its timestamps set to now and its position after every
+// other todo, replacing any the caller set.
+func (s *todoServiceImpl) Create(ctx context.Context, input dto.Todo) (dto.Todo, error) {
+	if !input.Status.Valid() {
+		return dto.Todo{}, dto.ErrInvalidStatus
+	}
+	if !input.Priority.Valid() {
+		return dto.Todo{}, dto.ErrInvalidPriority
+	}
+	if err := s.checkParent(ctx, input); err != nil {
+		return dto.Todo{}, err
+	}
+	reminders, err := scheduleReminders(input.Due, input.Reminders)
+	if err != nil {
+		return dto.Todo{}, err
+	}
+	input.Reminders = reminders
+	if input.Position, err = s.nextPosition(ctx); err != nil {
+		return dto.Todo{}, err
+	}
+	input.CreatedAt, input.CompletedAt, input.Version = time.Time{}, nil, 1
+	input.Stamp(time.Now())
+	return s.writes.write(ctx, todoChange{
+		id:     input.Id,
+		action: dto.TodoActionCreated,
+		create: true,
+		apply: func(ctx context.Context) (*dto.Todo, dto.Todo, error) {
+			return nil, input, s.repo.Save(ctx, input)
+		},
+		project: func(current *dto.Todo, now time.Time) (dto.Todo, error) {
+			return input, nil
+		},
+		event: func(before *dto.Todo, after dto.Todo) dto.TodoEvent {
+			return dto.TodoCreated{Todo: after}
+		},
+	})
+}
 
-	return todos, nil
+// Update changes the status of a todo. Completing it first applies the subtask policy to the
+// subtasks below it that are not done; a subtask below a done parent cannot be reopened.
+func (s *todoServiceImpl) Update(ctx context.Context, input dto.TodoInputUpdateStatus) (dto.Todo, error) {
+	change := s.statusChange(ctx, input)
+	if input.Status == dto.TodoStatusDone {
+		change.cascade = s.cascadeSubtasks(input.Id, input.ExpectedVersion, s.subtasks.Complete,
+			func(subtask dto.Todo) bool { return subtask.Status != dto.TodoStatusDone },
+			func(subtask dto.Todo) todoChange {
+				return s.statusChange(ctx, dto.TodoInputUpdateStatus{Id: subtask.Id, Status: dto.TodoStatusDone})
+			},
+		)
+	}
+	return s.writes.write(ctx, change)
 }
 
-func (s *todoServiceImpl) Create(input dto.Todo) error {
-	if err := s.repo.Save(input); err != nil {
-		return err
+func (s *todoServiceImpl) statusChange(ctx context.Context, input dto.TodoInputUpdateStatus) todoChange {
+	return todoChange{
+		id:     input.Id,
+		action: dto.TodoActionUpdated,
+		apply: func(ctx context.Context) (*dto.Todo, dto.Todo, error) {
+			before, err := s.repo.FindByIdForUpdate(ctx, input.Id)
+			if err != nil {
+				return nil, dto.Todo{}, err
+			}
+			if err := dto.CheckTransition(before.Status, input.Status); err != nil {
+				return nil, dto.Todo{}, err
+			}
+			if err := s.checkStatus(ctx, before, input.Status); err != nil {
+				return nil, dto.Todo{}, err
+			}
+			updated, err := s.repo.Update(ctx, input)
+			return &before, updated, err
+		},
+		project: func(current *dto.Todo, now time.Time) (dto.Todo, error) {
+			if err := activeVersion(current, input.ExpectedVersion); err != nil {
+				return dto.Todo{}, err
+			}
+			if err := dto.CheckTransition(current.Status, input.Status); err != nil {
+				return dto.Todo{}, err
+			}
+			if err := s.checkStatus(ctx, *current, input.Status); err != nil {
+				return dto.Todo{}, err
+			}
+			updated := *current
+			updated.Status = input.Status
+			updated.Stamp(now)
+			updated.Version++
+			return updated, nil
+		},
+		event: func(before *dto.Todo, after dto.Todo) dto.TodoEvent {
+			if before.Status == after.Status {
+				return nil
+			}
+			return dto.TodoStatusChanged{
+				TodoId:  after.Id,
+				From:    before.Status,
+				To:      after.Status,
+				Version: after.Version,
+			}
+		},
+	}
+}
+
+func (s *todoServiceImpl) Schedule(ctx context.Context, input dto.TodoInputSchedule) (dto.Todo, error) {
+	reminders, err := scheduleReminders(input.Due, input.Reminders)
+	if err != nil {
+		return dto.Todo{}, err
 	}
-	cachedData, err := s.cache.Get(context.Background(), "todos")
-	var newData []dto.Todo
+	input.Reminders = reminders
+
+	return s.writes.write(ctx, todoChange{
+		id:     input.Id,
+		action: dto.TodoActionScheduled,
+		apply: func(ctx context.Context) (*dto.Todo, dto.Todo, error) {
+			before, err := s.repo.FindByIdForUpdate(ctx, input.Id)
+			if err != nil {
+				return nil, dto.Todo{}, err
+			}
+			scheduled, err := s.repo.Schedule(ctx, input)
+			return &before, scheduled, err
+		},
+		project: func(current *dto.Todo, now time.Time) (dto.Todo, error) {
+			if err := activeVersion(current, input.ExpectedVersion); err != nil {
+				return dto.Todo{}, err
+			}
+			scheduled := *current
+			scheduled.Due, scheduled.Reminders = input.Due, input.Reminders
+			scheduled.Stamp(now)
+			scheduled.Version++
+			return scheduled, nil
+		},
+		event: func(before *dto.Todo, after dto.Todo) dto.TodoEvent {
+			return dto.TodoScheduled{TodoId: after.Id, Due: after.Due, Reminders: after.Reminders, Version: after.Version}
+		},
+	})
+}
 
+// Move places a todo between its new neighbours, giving it their priority as the list ranks by
+// priority before position. With no neighbours named, a set Priority moves the todo to the end of
+// that priority. Only the moved todo is written. Two moves into the same gap at once can get the
+// same position; the id then orders them.
+func (s *todoServiceImpl) Move(ctx context.Context, input dto.TodoInputMove) (dto.Todo, error) {
+	if input.Priority != nil && !input.Priority.Valid() {
+		return dto.Todo{}, dto.ErrInvalidPriority
+	}
+	priority, position, err := s.movePosition(ctx, input)
 	if err != nil {
-		// If cache miss, fetch all todos from DB
-		todos, err := s.repo.FindAll()
-		if err != nil {
-			return err
+		return dto.Todo{}, err
+	}
+	input.Priority, input.Position = &priority, position
+
+	return s.writes.write(ctx, todoChange{
+		id:     input.Id,
+		action: dto.TodoActionMoved,
+		apply: func(ctx context.Context) (*dto.Todo, dto.Todo, error) {
+			before, err := s.repo.FindByIdForUpdate(ctx, input.Id)
+			if err != nil {
+				return nil, dto.Todo{}, err
+			}
+			moved, err := s.repo.Move(ctx, input)
+			return &before, moved, err
+		},
+		project: func(current *dto.Todo, now time.Time) (dto.Todo, error) {
+			if err := activeVersion(current, input.ExpectedVersion); err != nil {
+				return dto.Todo{}, err
+			}
+			moved := *current
+			moved.Priority, moved.Position = *input.Priority, input.Position
+			moved.Stamp(now)
+			moved.Version++
+			return moved, nil
+		},
+		event: func(before *dto.Todo, after dto.Todo) dto.TodoEvent {
+			return dto.TodoMoved{TodoId: after.Id, Priority: after.Priority, Position: after.Position, Version: after.Version}
+		},
+	})
+}
+
+// movePosition returns the priority and position that place a todo between the neighbours named by
+// input. The neighbours must share a priority, and match Priority when it is set. When only one
+// neighbour is named, the todo next to it on the other side within that priority becomes the
+// other bound.
+func (s *todoServiceImpl) movePosition(ctx context.Context, input dto.TodoInputMove) (dto.TodoPriority, string, error) {
+	if input.After == input.Id || input.Before == input.Id {
+		return 0, "", dto.ErrInvalidMove
+	}
+	if input.After == "" && input.Before == "" {
+		if input.Priority == nil {
+			return 0, "", dto.ErrInvalidMove
 		}
+		position, err := s.nextPosition(ctx)
+		return *input.Priority, position, err
+	}
 
-		newData = todos
-	} else {
-		// Cache hit, unmarshal and append the new todo
-		if err := json.Unmarshal([]byte(cachedData), &newData); err != nil {
-			return err
+	var neighbours []dto.Todo
+	for _, id := range []string{input.After, input.Before} {
+		if id == "" {
+			continue
+		}
+		neighbour, err := s.findById(ctx, id)
+		if errors.Is(err, dto.ErrTodoNotFound) {
+			return 0, "", dto.ErrInvalidMove
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		neighbours = append(neighbours, neighbour)
+	}
+	priority := neighbours[0].Priority
+	if input.Priority != nil {
+		priority = *input.Priority
+	}
+	for _, neighbour := range neighbours {
+		if neighbour.Priority != priority {
+			return 0, "", dto.ErrInvalidMove
 		}
-		newData = append(newData, input)
 	}
 
-	// Marshal and update the cache
-	data, err := json.Marshal(newData)
+	var lower, upper string
+	var err error
+	switch {
+	case input.Before == "":
+		lower = neighbours[0].Position
+		upper, err = s.repo.AdjacentPosition(ctx, priority, lower, true)
+	case input.After == "":
+		upper = neighbours[0].Position
+		lower, err = s.repo.AdjacentPosition(ctx, priority, upper, false)
+	default:
+		lower, upper = neighbours[0].Position, neighbours[1].Position
+	}
 	if err != nil {
-		return err
+		return 0, "", err
 	}
 
-	return s.cache.Set(context.Background(), "todos", string(data), 0)
+	position, err := dto.PositionBetween(lower, upper)
+	if errors.Is(err, dto.ErrInvalidPosition) {
+		return 0, "", dto.ErrInvalidMove
+	}
+	return priority, position, err
 }
 
-func (s *todoServiceImpl) Update(input dto.TodoInputUpdateStatus) error {
-	if err := s.repo.Update(input); err != nil {
-		return err
+// nextPosition returns a position after every live todo. The repository does not see creates and
+// moves still in the write-behind buffer, so the position is also kept after every position this
+// service handed out; two instances doing this at once can still pick the same one, which the id
+// then orders.
+func (s *todoServiceImpl) nextPosition(ctx context.Context) (string, error) {
+	last, err := s.repo.LastPosition(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.positions.Lock()
+	defer s.positions.Unlock()
+	position, err := dto.PositionBetween(max(last, s.positions.last), "")
+	if err != nil {
+		return "", err
+	}
+	s.positions.last = position
+	return position, nil
+}
+
+// scheduleReminders normalizes reminders, which need a due date to count back from.
+func scheduleReminders(due dto.DueDate, reminders dto.ReminderOffsets) (dto.ReminderOffsets, error) {
+	reminders, err := reminders.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if due.IsZero() && len(reminders) > 0 {
+		return nil, dto.ErrInvalidDue
 	}
-	cacheData, err := s.cache.Get(context.Background(), "todos")
+	return reminders, nil
+}
 
+// Overdue lists todos that are not done and whose due date has passed, the longest overdue first.
+func (s *todoServiceImpl) Overdue(ctx context.Context, limit int) ([]dto.Todo, error) {
+	limit, err := dueLimit(limit)
 	if err != nil {
-		todos, err := s.repo.FindAll()
+		return nil, err
+	}
+	return s.repo.FindDue(ctx, dto.TimeRange{Before: time.Now()}, limit)
+}
+
+// DueWithin lists todos that are not done and fall due in the next within, the soonest first.
+func (s *todoServiceImpl) DueWithin(ctx context.Context, within time.Duration, limit int) ([]dto.Todo, error) {
+	limit, err := dueLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	if within <= 0 {
+		return nil, dto.ErrInvalidQuery
+	}
+	now := time.Now()
+	return s.repo.FindDue(ctx, dto.TimeRange{After: now, Before: now.Add(within)}, limit)
+}
+
+func dueLimit(limit int) (int, error) {
+	if limit == 0 {
+		return dto.DefaultTodoLimit, nil
+	}
+	if limit < 0 || limit > dto.MaxTodoLimit {
+		return 0, dto.ErrInvalidQuery
+	}
+	return limit, nil
+}
 
+// Delete moves a todo to the trash, first applying the subtask policy to the live subtasks below it.
+func (s *todoServiceImpl) Delete(ctx context.Context, input dto.TodoInputDelete) error {
+	change := s.deleteChange(input)
+	change.cascade = s.cascadeSubtasks(input.Id, input.ExpectedVersion, s.subtasks.Delete,
+		func(subtask dto.Todo) bool { return true },
+		func(subtask dto.Todo) todoChange {
+			return s.deleteChange(dto.TodoInputDelete{Id: subtask.Id})
+		},
+	)
+	_, err := s.writes.write(ctx, change)
+	return err
+}
+
+func (s *todoServiceImpl) deleteChange(input dto.TodoInputDelete) todoChange {
+	return todoChange{
+		id:     input.Id,
+		action: dto.TodoActionDeleted,
+		apply: func(ctx context.Context) (*dto.Todo, dto.Todo, error) {
+			before, err := s.repo.FindByIdForUpdate(ctx, input.Id)
+			if err != nil {
+				return nil, dto.Todo{}, err
+			}
+			deleted, err := s.repo.Delete(ctx, input)
+			return &before, deleted, err
+		},
+		project: func(current *dto.Todo, now time.Time) (dto.Todo, error) {
+			if err := activeVersion(current, input.ExpectedVersion); err != nil {
+				return dto.Todo{}, err
+			}
+			deleted := *current
+			deleted.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+			deleted.Stamp(now)
+			deleted.Version++
+			return deleted, nil
+		},
+		event: func(before *dto.Todo, after dto.Todo) dto.TodoEvent {
+			return dto.TodoDeleted{TodoId: after.Id, Version: after.Version}
+		},
+	}
+}
+
+func (s *todoServiceImpl) FindTrash(ctx context.Context) ([]dto.Todo, error) {
+	return s.repo.FindTrash(ctx)
+}
+
+// Restore brings a todo back from the trash, provided its parent is live and, unless the todo is
+// done, not done.
+func (s *todoServiceImpl) Restore(ctx context.Context, id string) (dto.Todo, error) {
+	return s.writes.write(ctx, todoChange{
+		id:     id,
+		action: dto.TodoActionRestored,
+		apply: func(ctx context.Context) (*dto.Todo, dto.Todo, error) {
+			before, err := s.repo.FindByIdForUpdate(ctx, id)
+			if err != nil {
+				return nil, dto.Todo{}, err
+			}
+			if before.DeletedAt.Valid {
+				if err := s.checkParent(ctx, before); err != nil {
+					return nil, dto.Todo{}, err
+				}
+			}
+			restored, err := s.repo.Restore(ctx, id)
+			return &before, restored, err
+		},
+		project: func(current *dto.Todo, now time.Time) (dto.Todo, error) {
+			if !current.DeletedAt.Valid {
+				return dto.Todo{}, dto.ErrTodoNotFound
+			}
+			if err := s.checkParent(ctx, *current); err != nil {
+				return dto.Todo{}, err
+			}
+			restored := *current
+			restored.DeletedAt = gorm.DeletedAt{}
+			restored.Stamp(now)
+			restored.Version++
+			return restored, nil
+		},
+		event: func(before *dto.Todo, after dto.Todo) dto.TodoEvent {
+			return dto.TodoRestored{Todo: after}
+		},
+	})
+}
+
+func (s *todoServiceImpl) Purge(ctx context.Context, id string) error {
+	var before dto.Todo
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		before, err = s.repo.FindByIdForUpdate(ctx, id)
 		if err != nil {
 			return err
 		}
+		if err := s.repo.Purge(ctx, id); err != nil {
+			return err
+		}
+		return s.record(ctx, dto.TodoActionPurged, id, &before, nil)
+	})
+	if err != nil {
+		return err
+	}
 
-		data, err := json.Marshal(todos)
+	s.writes.purged(ctx, []dto.Todo{before})
+	return nil
+}
+
+func (s *todoServiceImpl) PurgeDeletedBefore(ctx context.Context, before time.Time) (int, error) {
+	var purged []dto.Todo
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		purged, err = s.repo.PurgeDeletedBefore(ctx, before)
 		if err != nil {
 			return err
 		}
-		return s.cache.Set(context.Background(), "todos", string(data), 0)
+		for _, todo := range purged {
+			if err := s.record(ctx, dto.TodoActionPurged, todo.Id, &todo, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(purged) == 0 {
+		return 0, nil
 	}
 
-	var todos []dto.Todo
-	if err := json.Unmarshal([]byte(cacheData), &todos); err != nil {
-		return err
+	s.writes.purged(ctx, purged)
+	return len(purged), nil
+}
+
+func (s *todoServiceImpl) History(ctx context.Context, id string) ([]dto.TodoHistory, error) {
+	entries, err := s.history.FindByTodoId(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, dto.ErrTodoNotFound
 	}
+	return entries, nil
+}
 
-	for index, todo := range todos {
-		if todo.Id == input.Id {
-			todos[index].Status = input.Status
-			break
+// commit applies change to the repository together with its audit entry and event, in one
+// transaction with the changes its cascade returns, and returns every todo written, change's last.
+func (s *todoServiceImpl) commit(ctx context.Context, change todoChange) ([]dto.Todo, error) {
+	var written []dto.Todo
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		changes := []todoChange{change}
+		if change.cascade != nil {
+			cascaded, err := change.cascade(ctx, s.repo.FindByIdForUpdate)
+			if err != nil {
+				return err
+			}
+			changes = append(cascaded, change)
+		}
+		for _, change := range changes {
+			before, after, err := change.apply(ctx)
+			if err != nil {
+				return err
+			}
+			if err := s.record(ctx, change.action, change.id, before, &after); err != nil {
+				return err
+			}
+			written = append(written, after)
+			if change.event == nil {
+				continue
+			}
+			if event := change.event(before, after); event != nil {
+				if err := s.emit(ctx, event); err != nil {
+					return err
+				}
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return written, nil
+}
 
-	data, err := json.Marshal(todos)
+// record appends an audit entry attributed to the actor and request id carried by ctx.
+func (s *todoServiceImpl) record(ctx context.Context, action string, todoId string, before *dto.Todo, after *dto.Todo) error {
+	return s.history.Append(ctx, s.historyEntry(ctx, action, todoId, before, after))
+}
+
+func (s *todoServiceImpl) historyEntry(ctx context.Context, action string, todoId string, before *dto.Todo, after *dto.Todo) dto.TodoHistory {
+	return dto.TodoHistory{
+		TodoId:    todoId,
+		Action:    action,
+		Before:    before,
+		After:     after,
+		Actor:     requestctx.Actor(ctx),
+		RequestId: requestctx.RequestId(ctx),
+		CreatedAt: time.Now(),
+	}
+}
+
+// emit stores event in the outbox so it commits or rolls back with the surrounding write.
+func (s *todoServiceImpl) emit(ctx context.Context, event dto.TodoEvent) error {
+	outboxEvent, err := dto.NewOutboxEvent(event, time.Now())
 	if err != nil {
 		return err
 	}
+	return s.outbox.Append(ctx, outboxEvent)
+}
+
+// listKey names the cached page for query under the current list generation, so bumping
+// the generation invalidates every cached page at once.
+func (s *todoServiceImpl) listKey(ctx context.Context, query dto.TodoQuery) string {
+	generation, err := s.cache.Get(ctx, s.keys.generation())
+	if err != nil {
+		cacheFailed(ctx, "get", s.keys.generation(), err)
+		generation = "0"
+	}
 
-	return s.cache.Set(context.Background(), "todos", string(data), 0)
+	canonical := fmt.Sprintf("%d|%s|%s|%s|%s|%s|%s|%t",
+		query.Limit, query.Cursor, query.Status, query.Created, query.Updated, query.Completed, query.Sort, query.IncludeTotal)
+	hash := sha1.Sum([]byte(canonical))
+	return s.keys.list(generation, hex.EncodeToString(hash[:]))
 }
 
-func (s *todoServiceImpl) Delete(input dto.TodoInputDelete) error {
-	err := s.repo.Delete(input)
+// cachedPage assembles a cached list page from its id index and the item keys, and reports whether
+// it is still fresh. Any missing piece counts as a miss so the page is reloaded as a whole.
+func (s *todoServiceImpl) cachedPage(ctx context.Context, key string) (dto.TodoPage, bool, bool) {
+	data, err := s.cache.Get(ctx, key)
 	if err != nil {
-		return err
+		cacheFailed(ctx, "get", key, err)
+		return dto.TodoPage{}, false, false
+	}
+	var index dto.CachedTodoPage
+	if err := s.codec.Unmarshal([]byte(data), &index); err != nil {
+		s.dropCorrupted(ctx, key, err)
+		return dto.TodoPage{}, false, false
 	}
 
-	cacheData, err := s.cache.Get(context.Background(), "todos")
+	keys := make([]string, 0, len(index.Ids))
+	for _, id := range index.Ids {
+		keys = append(keys, s.keys.item(id))
+	}
+	items, err := s.cache.MGet(ctx, keys)
 	if err != nil {
-		todos, err := s.repo.FindAll()
+		cacheFailed(ctx, "mget", key, err)
+		return dto.TodoPage{}, false, false
+	}
+
+	page := dto.TodoPage{Todos: make([]dto.Todo, 0, len(keys)), NextCursor: index.NextCursor, Total: index.Total}
+	for _, key := range keys {
+		item, ok := items[key]
+		if !ok {
+			return dto.TodoPage{}, false, false
+		}
+		todo, err := decodeTodo(s.codec, item)
 		if err != nil {
-			return err
+			s.dropCorrupted(ctx, key, err)
+			return dto.TodoPage{}, false, false
+		}
+		if todo.DeletedAt.Valid {
+			return dto.TodoPage{}, false, false
 		}
+		page.Todos = append(page.Todos, todo)
+	}
+	return page, time.Now().Before(index.FreshUntil), true
+}
 
-		data, err := json.Marshal(todos)
+// cacheTodos writes todos to their item keys for ttl; an older version never replaces a newer one.
+func (s *todoServiceImpl) cacheTodos(ctx context.Context, ttl time.Duration, todos ...dto.Todo) {
+	if len(todos) == 0 {
+		return
+	}
+	values := make(map[string]string, len(todos))
+	for _, todo := range todos {
+		value, err := encodeTodo(s.codec, todo)
 		if err != nil {
-			return err
+			cacheFailed(ctx, "encode", s.keys.item(todo.Id), err)
+			return
 		}
-		return s.cache.Set(context.Background(), "todos", string(data), 0)
+		values[s.keys.item(todo.Id)] = value
 	}
+	if err := s.cache.SetIfNewer(ctx, values, ttl); err != nil {
+		cacheFailed(ctx, "set", s.keys.item(todos[0].Id), err)
+	}
+}
 
-	var todos []dto.Todo
+// refresh stores the written todos and invalidates every cached list page. The write has already
+// committed, so a cache failure is only logged; the TTLs bound how long readers see the old data.
+func (s *todoServiceImpl) refresh(ctx context.Context, todos ...dto.Todo) {
+	s.cacheTodos(ctx, s.ttl.Item, todos...)
+	s.expireLists(ctx)
+}
 
-	if err := json.Unmarshal([]byte(cacheData), &todos); err != nil {
-		return err
+// evict deletes the written todos and every cached list page, so the next reads load them again.
+func (s *todoServiceImpl) evict(ctx context.Context, todos ...dto.Todo) {
+	keys := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		keys = append(keys, s.keys.item(todo.Id))
+	}
+	if err := s.cache.Del(ctx, keys...); err != nil {
+		cacheFailed(ctx, "delete", keys[0], err)
 	}
+	s.expireLists(ctx)
+}
 
-	var newData []dto.Todo
-	for _, todo := range todos {
-		if todo.Id != input.Id {
-			newData = append(newData, todo)
-		}
+func (s *todoServiceImpl) expireLists(ctx context.Context) {
+	if err := invalidateLists(ctx, s.cache, s.keys); err != nil {
+		cacheFailed(ctx, "set", s.keys.generation(), err)
 	}
+}
 
-	data, err := json.Marshal(newData)
-	if err != nil {
-		return err
+// invalidateLists moves to a new list generation so every cached page is ignored. The generation
+// never expires: falling back to "0" could revive pages cached under an earlier "0".
+func invalidateLists(ctx context.Context, c cache.Cache, keys todoKeys) error {
+	return c.Set(ctx, keys.generation(), strconv.FormatInt(time.Now().UnixNano(), 10), 0)
+}
+
+// dropCorrupted deletes an entry that no longer decodes so it is rebuilt from the repository.
+func (s *todoServiceImpl) dropCorrupted(ctx context.Context, key string, err error) {
+	cacheFailed(ctx, "decode", key, err)
+	if err := s.cache.Del(ctx, key); err != nil {
+		cacheFailed(ctx, "delete", key, err)
 	}
+}
+
+// cacheFailed logs a cache error the service recovers from by using the repository. Misses are
+// expected and a bypassed cache is reported by the health check, so neither is logged.
+func cacheFailed(ctx context.Context, operation string, key string, err error) {
+	if errors.Is(err, cache.ErrCacheMiss) || errors.Is(err, cache.ErrCacheBypassed) {
+		return
+	}
+	logger.Log.Warn("Cache "+operation+" failed, using the repository.",
+		zap.String("X-Request-ID", requestctx.RequestId(ctx)),
+		zap.String("key", key),
+		zap.Error(err),
+	)
+}
 
-	return s.cache.Set(context.Background(), "todos", string(data), 0)
+// tombstone marks a purged todo in the cache with a version no in-flight reload can beat, until
+// the item key expires.
+func tombstone(todo dto.Todo) dto.Todo {
+	todo.Version = math.MaxInt64
+	return todo
 }
@@ -1,83 +1,99 @@
 package service_test
 
 import (
+	"context"
 	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/memory"
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/serialization"
 	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/requestctx"
 	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
 	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
 	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
 )
 
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+var listKey = mock.MatchedBy(func(key string) bool {
+	return strings.HasPrefix(key, "todos:json:v1:list:")
+})
+
+// cachedItem matches a SetIfNewer call that stores the todo with id.
+func cachedItem(id string) interface{} {
+	return mock.MatchedBy(func(values map[string]string) bool {
+		_, ok := values["todos:json:v1:item:"+id]
+		return ok
+	})
+}
+
 func TestTodoserviceFindAllByTodo(t *testing.T) {
+	page := dto.TodoPage{
+		Todos: []dto.Todo{
+			{
+				Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
+				Topic:       "Complete Project",
+				Description: "Description for Complete Project",
+				Status:      "done",
+				Version:     1,
+			},
+		},
+		NextCursor: "eyJzIjoiaWQifQ",
+	}
+
 	testCases := []struct {
 		description string
+		query       dto.TodoQuery
 		repoReturn  struct {
-			todos []dto.Todo
-			err   error
+			page dto.TodoPage
+			err  error
 		}
 		cacheGetReturn struct {
 			data string
 			err  error
 		}
 		cacheSetReturn error
-		expected       []dto.Todo
+		expected       dto.TodoPage
 		expectedErr    error
 	}{
 		// Cache hit scenario
 		{
 			description: "Cache hit",
-			repoReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{
-				todos: []dto.Todo{
-					{
-						Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
-						Topic:       "Complete Project",
-						Description: "Description for Complete Project",
-						Status:      "Completed",
-					},
-				},
-				err: nil,
-			},
+			query:       dto.TodoQuery{},
 			cacheGetReturn: struct {
 				data string
 				err  error
 			}{
-				data: "[{\"id\":\"1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412\",\"topic\":\"Complete Project\",\"description\":\"Description for Complete Project\",\"status\":\"Completed\"}]",
+				data: "{\"ids\":[\"1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412\"],\"next_cursor\":\"eyJzIjoiaWQifQ\",\"fresh_until\":\"2999-01-01T00:00:00Z\"}",
 				err:  nil,
 			},
 			cacheSetReturn: nil,
-			expected: []dto.Todo{
-				{
-					Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
-					Topic:       "Complete Project",
-					Description: "Description for Complete Project",
-					Status:      "Completed",
-				},
-			},
-			expectedErr: nil,
+			expected:       page,
+			expectedErr:    nil,
 		},
 		// Cache miss scenario
 		{
 			description: "Cache miss",
+			query:       dto.TodoQuery{Limit: 1, Status: "done", Sort: "-topic"},
 			repoReturn: struct {
-				todos []dto.Todo
-				err   error
+				page dto.TodoPage
+				err  error
 			}{
-				todos: []dto.Todo{
-					{
-						Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
-						Topic:       "Complete Project",
-						Description: "Description for Complete Project",
-						Status:      "Completed",
-					},
-				},
-				err: nil,
+				page: page,
+				err:  nil,
 			},
 			cacheGetReturn: struct {
 				data string
@@ -87,25 +103,19 @@ func TestTodoserviceFindAllByTodo(t *testing.T) {
 				err:  errors.New("failed to get cache"),
 			},
 			cacheSetReturn: nil,
-			expected: []dto.Todo{
-				{
-					Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
-					Topic:       "Complete Project",
-					Description: "Description for Complete Project",
-					Status:      "Completed",
-				},
-			},
-			expectedErr: nil,
+			expected:       page,
+			expectedErr:    nil,
 		},
 		// Repository error scenario
 		{
 			description: "Repository error",
+			query:       dto.TodoQuery{},
 			repoReturn: struct {
-				todos []dto.Todo
-				err   error
+				page dto.TodoPage
+				err  error
 			}{
-				todos: nil,
-				err:   errors.New("failed to fetch todos"),
+				page: dto.TodoPage{},
+				err:  errors.New("failed to fetch todos"),
 			},
 			cacheGetReturn: struct {
 				data string
@@ -115,25 +125,18 @@ func TestTodoserviceFindAllByTodo(t *testing.T) {
 				err:  errors.New("failed to get cache"),
 			},
 			cacheSetReturn: nil,
-			expected:       nil,
 			expectedErr:    errors.New("failed to fetch todos"),
 		},
 		// Cache error scenario
 		{
-			description: "Cache error",
+			description: "Cache error falls back to the repository",
+			query:       dto.TodoQuery{},
 			repoReturn: struct {
-				todos []dto.Todo
-				err   error
+				page dto.TodoPage
+				err  error
 			}{
-				todos: []dto.Todo{
-					{
-						Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
-						Topic:       "Complete Project",
-						Description: "Description for Complete Project",
-						Status:      "Completed",
-					},
-				},
-				err: nil,
+				page: page,
+				err:  nil,
 			},
 			cacheGetReturn: struct {
 				data string
@@ -143,8 +146,14 @@ func TestTodoserviceFindAllByTodo(t *testing.T) {
 				err:  errors.New("failed to get cache"),
 			},
 			cacheSetReturn: errors.New("failed to set cache"),
-			expected:       nil,
-			expectedErr:    errors.New("failed to set cache"),
+			expected:       page,
+			expectedErr:    nil,
+		},
+		// Invalid query scenario
+		{
+			description: "Unknown sort field",
+			query:       dto.TodoQuery{Sort: "owner"},
+			expectedErr: dto.ErrInvalidQuery,
 		},
 	}
 
@@ -154,20 +163,36 @@ func TestTodoserviceFindAllByTodo(t *testing.T) {
 			todoRepo := repository.NewTodoRepositoryMock()
 
 			todoCache := cache.NewRedisCacheMock()
+			todoHistory := repository.NewTodoHistoryRepositoryMock()
+			todoOutbox := repository.NewOutboxRepositoryMock()
 
-			todoCache.On("Get", mock.Anything, "todos").Return(testCase.cacheGetReturn.data, testCase.cacheGetReturn.err)
+			if testCase.expectedErr != dto.ErrInvalidQuery {
+				normalized, _ := testCase.query.Normalize()
+				todoCache.On("Get", mock.Anything, "todos:json:v1:gen").Return("1", nil)
+				if testCase.expectedErr == nil {
+					todoRepo.On("Progress", mock.Anything, []string{page.Todos[0].Id}).Return(map[string]dto.TodoProgress{}, nil)
+				}
+				todoCache.On("Get", mock.Anything, listKey).Return(testCase.cacheGetReturn.data, testCase.cacheGetReturn.err)
 
-			if testCase.cacheGetReturn.err != nil {
-				todoRepo.On("FindAll").Return(testCase.repoReturn.todos, testCase.repoReturn.err)
-				if testCase.repoReturn.err == nil {
-					todoCache.On("Set", mock.Anything, "todos", mock.Anything, mock.Anything).Return(testCase.cacheSetReturn)
+				if testCase.cacheGetReturn.err != nil {
+					todoRepo.On("FindAll", mock.Anything, normalized).Return(testCase.repoReturn.page, testCase.repoReturn.err)
+					if testCase.repoReturn.err == nil {
+						todoCache.On("SetIfNewer", mock.Anything, cachedItem(page.Todos[0].Id), mock.Anything).Return(nil)
+						todoCache.On("Set", mock.Anything, listKey, mock.MatchedBy(func(data string) bool {
+							return strings.HasPrefix(data, "{\"ids\":[\"1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412\"],\"next_cursor\":\"eyJzIjoiaWQifQ\",\"fresh_until\":")
+						}), mock.Anything).Return(testCase.cacheSetReturn)
+					}
+				} else {
+					todoCache.On("MGet", mock.Anything, []string{"todos:json:v1:item:1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412"}).Return(map[string]string{
+						"todos:json:v1:item:1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412": "1:{\"id\":\"1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412\",\"topic\":\"Complete Project\",\"description\":\"Description for Complete Project\",\"status\":\"done\",\"version\":1}",
+					}, nil)
 				}
 			}
 
-			todoService := service.NewTodoService(todoRepo, todoCache)
+			todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
 
 			// Act
-			response, err := todoService.FindAll()
+			response, err := todoService.FindAll(context.Background(), testCase.query)
 
 			// Assert
 			if testCase.expectedErr != nil {
@@ -179,56 +204,176 @@ func TestTodoserviceFindAllByTodo(t *testing.T) {
 			}
 			todoRepo.AssertExpectations(t)
 			todoCache.AssertExpectations(t)
+			todoHistory.AssertExpectations(t)
+			todoOutbox.AssertExpectations(t)
 		})
 	}
 }
 
+func TestTodoserviceFindAllCacheKeys(t *testing.T) {
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+	todoHistory := repository.NewTodoHistoryRepositoryMock()
+	todoOutbox := repository.NewOutboxRepositoryMock()
+
+	var keys []string
+	todoCache.On("Get", mock.Anything, "todos:json:v1:gen").Return("1", nil)
+	todoCache.On("Get", mock.Anything, listKey).Return("", errors.New("miss")).Run(func(args mock.Arguments) {
+		keys = append(keys, args.String(1))
+	})
+	todoRepo.On("FindAll", mock.Anything, mock.Anything).Return(dto.TodoPage{Todos: []dto.Todo{}}, nil)
+	todoCache.On("Set", mock.Anything, listKey, mock.Anything, mock.Anything).Return(nil)
+
+	todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+
+	_, err := todoService.FindAll(context.Background(), dto.TodoQuery{Status: "pending"})
+	assert.NoError(t, err)
+	_, err = todoService.FindAll(context.Background(), dto.TodoQuery{Status: "done"})
+	assert.NoError(t, err)
+	_, err = todoService.FindAll(context.Background(), dto.TodoQuery{Status: "pending", Limit: dto.DefaultTodoLimit, Sort: dto.DefaultTodoSort})
+	assert.NoError(t, err)
+
+	assert.Len(t, keys, 3)
+	assert.NotEqual(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], keys[2])
+}
+
+func TestTodoserviceFindAllCoalescesLoads(t *testing.T) {
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+
+	release := make(chan struct{})
+	todoCache.On("Get", mock.Anything, "todos:json:v1:gen").Return("1", nil)
+	todoCache.On("Get", mock.Anything, listKey).Return("", errors.New("miss"))
+	todoRepo.On("FindAll", mock.Anything, mock.Anything).Return(dto.TodoPage{Todos: []dto.Todo{}}, nil).Once().Run(func(args mock.Arguments) {
+		<-release
+	})
+	todoCache.On("Set", mock.Anything, listKey, mock.Anything, mock.Anything).Return(nil).Once()
+
+	todoService := service.NewTodoService(todoRepo, repository.NewTodoHistoryRepositoryMock(), repository.NewOutboxRepositoryMock(), repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := todoService.FindAll(context.Background(), dto.TodoQuery{})
+			assert.NoError(t, err)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	todoRepo.AssertNumberOfCalls(t, "FindAll", 1)
+}
+
+func TestTodoserviceFindAllStopsWaitingOnCancel(t *testing.T) {
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+
+	release := make(chan struct{})
+	cached := make(chan struct{})
+	todoCache.On("Get", mock.Anything, "todos:json:v1:gen").Return("1", nil)
+	todoCache.On("Get", mock.Anything, listKey).Return("", errors.New("miss"))
+	todoRepo.On("FindAll", mock.MatchedBy(func(ctx context.Context) bool {
+		_, ok := ctx.Deadline()
+		return ok
+	}), mock.Anything).Return(dto.TodoPage{Todos: []dto.Todo{}}, nil).Once().Run(func(args mock.Arguments) {
+		<-release
+	})
+	todoCache.On("Set", mock.Anything, listKey, mock.Anything, mock.Anything).Return(nil).Once().Run(func(args mock.Arguments) {
+		close(cached)
+	})
+
+	todoService := service.NewTodoService(todoRepo, repository.NewTodoHistoryRepositoryMock(), repository.NewOutboxRepositoryMock(), repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := todoService.FindAll(ctx, dto.TodoQuery{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// The load the caller gave up on still finishes and caches the page.
+	close(release)
+	select {
+	case <-cached:
+	case <-time.After(time.Second):
+		t.Fatal("abandoned load was not cached")
+	}
+	todoRepo.AssertExpectations(t)
+}
+
+func TestTodoserviceFindAllServesStale(t *testing.T) {
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+
+	stale := "{\"ids\":[\"1\"],\"fresh_until\":\"2000-01-01T00:00:00Z\"}"
+	refreshed := make(chan struct{})
+	todoCache.On("Get", mock.Anything, "todos:json:v1:gen").Return("1", nil)
+	todoCache.On("Get", mock.Anything, listKey).Return(stale, nil)
+	todoCache.On("MGet", mock.Anything, []string{"todos:json:v1:item:1"}).Return(map[string]string{
+		"todos:json:v1:item:1": "1:{\"id\":\"1\",\"topic\":\"Stale\",\"version\":1}",
+	}, nil)
+	todoRepo.On("Progress", mock.Anything, []string{"1"}).Return(map[string]dto.TodoProgress{}, nil)
+	todoRepo.On("FindAll", mock.Anything, mock.Anything).Return(dto.TodoPage{Todos: []dto.Todo{}}, nil).Once()
+	todoCache.On("Set", mock.Anything, listKey, mock.Anything, mock.Anything).Return(nil).Once().Run(func(args mock.Arguments) {
+		close(refreshed)
+	})
+
+	todoService := service.NewTodoService(todoRepo, repository.NewTodoHistoryRepositoryMock(), repository.NewOutboxRepositoryMock(), repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+
+	page, err := todoService.FindAll(context.Background(), dto.TodoQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Stale", page.Todos[0].Topic)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale page was not refreshed")
+	}
+	todoRepo.AssertExpectations(t)
+}
+
+func TestTodoserviceFindAllDropsCorruptedPage(t *testing.T) {
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+
+	page := dto.TodoPage{Todos: []dto.Todo{}}
+	todoCache.On("Get", mock.Anything, "todos:json:v1:gen").Return("1", nil)
+	todoCache.On("Get", mock.Anything, listKey).Return("{not json", nil)
+	todoCache.On("Del", mock.Anything, mock.MatchedBy(func(keys []string) bool {
+		return len(keys) == 1 && strings.HasPrefix(keys[0], "todos:json:v1:list:1:")
+	})).Return(errors.New("connection refused")).Once()
+	todoRepo.On("FindAll", mock.Anything, mock.Anything).Return(page, nil)
+	todoCache.On("Set", mock.Anything, listKey, mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	todoService := service.NewTodoService(todoRepo, repository.NewTodoHistoryRepositoryMock(), repository.NewOutboxRepositoryMock(), repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+
+	response, err := todoService.FindAll(context.Background(), dto.TodoQuery{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, page, response)
+	todoRepo.AssertExpectations(t)
+	todoCache.AssertExpectations(t)
+}
+
 func TestTodoserviceCreateTodo(t *testing.T) {
 	testCases := []struct {
-		description       string
-		input             dto.Todo
-		repoSaveReturn    error
-		repoFindAllReturn struct {
-			todos []dto.Todo
-			err   error
-		}
-		cacheGetReturn struct {
-			data string
-			err  error
-		}
+		description    string
+		input          dto.Todo
+		repoSaveReturn error
 		cacheSetReturn error
 		expectedErr    error
 	}{
 		{
-			description: "Create todo success repo and cache hit",
+			description: "Create todo success",
 			input: dto.Todo{
 				Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
 				Topic:       "Complete Project",
 				Description: "Description for Complete Project",
-				Status:      "Completed",
+				Status:      "done",
 			},
 			repoSaveReturn: nil,
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{
-				todos: []dto.Todo{
-					{
-						Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
-						Topic:       "Complete Project",
-						Description: "Description for Complete Project",
-						Status:      "Completed",
-					},
-				},
-				err: nil,
-			},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{
-				data: "[{\"id\":\"1e89f1d7-78c5-4d4a-bae3-d4f5f96a7411\",\"topic\":\"Complete Project\",\"description\":\"Description for Complete Project\",\"status\":\"Completed\"}]",
-				err:  nil,
-			},
 			cacheSetReturn: nil,
 			expectedErr:    nil,
 		},
@@ -238,50 +383,22 @@ func TestTodoserviceCreateTodo(t *testing.T) {
 				Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
 				Topic:       "Complete Project",
 				Description: "Description for Complete Project",
-				Status:      "Completed",
+				Status:      "done",
 			},
 			repoSaveReturn: errors.New("repository save failed"),
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{
-				todos: []dto.Todo{},
-				err:   nil,
-			},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{
-				data: "[{\"id\":\"1e89f1d7-78c5-4d4a-bae3-d4f5f96a7411\",\"topic\":\"Complete Project\",\"description\":\"Description for Complete Project\",\"status\":\"Completed\"}]",
-				err:  nil,
-			},
 			cacheSetReturn: nil,
 			expectedErr:    errors.New("repository save failed"),
 		},
 		{
-			description: "Cache miss get",
+			description: "Create todo succeeds when cache invalidation fails",
 			input: dto.Todo{
 				Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
 				Topic:       "Complete Project",
 				Description: "Description for Complete Project",
-				Status:      "Completed",
+				Status:      "done",
 			},
 			repoSaveReturn: nil,
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{
-				todos: []dto.Todo{},
-				err:   nil,
-			},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{
-				data: "",
-				err:  errors.New("cache miss get"),
-			},
-			cacheSetReturn: nil,
+			cacheSetReturn: errors.New("failed to set cache"),
 			expectedErr:    nil,
 		},
 	}
@@ -291,22 +408,25 @@ func TestTodoserviceCreateTodo(t *testing.T) {
 			// Arrange
 			todoRepo := repository.NewTodoRepositoryMock()
 			todoCache := cache.NewRedisCacheMock()
-			todoRepo.On("Save", testCase.input).Return(testCase.repoSaveReturn)
+			todoHistory := repository.NewTodoHistoryRepositoryMock()
+			todoOutbox := repository.NewOutboxRepositoryMock()
+			todoRepo.On("LastPosition", mock.Anything).Return("i", nil)
+			todoRepo.On("Save", mock.Anything, mock.MatchedBy(stampedFrom(testCase.input))).Return(testCase.repoSaveReturn)
 			if testCase.repoSaveReturn == nil {
-
-				todoCache.On("Get", mock.Anything, "todos").Return(testCase.cacheGetReturn.data, testCase.cacheGetReturn.err)
-
-				if testCase.cacheGetReturn.err != nil {
-					todoRepo.On("FindAll").Return(testCase.repoFindAllReturn.todos, testCase.repoFindAllReturn.err)
-				}
-
-				todoCache.On("Set", mock.Anything, "todos", mock.Anything, mock.Anything).Return(testCase.cacheSetReturn)
+				todoHistory.On("Append", mock.Anything, mock.MatchedBy(func(entry dto.TodoHistory) bool {
+					return entry.Action == dto.TodoActionCreated && entry.Before == nil && stampedFrom(testCase.input)(*entry.After)
+				})).Return(nil)
+				todoOutbox.On("Append", mock.Anything, mock.MatchedBy(func(event dto.OutboxEvent) bool {
+					return event.EventType == dto.EventTodoCreated && event.AggregateId == testCase.input.Id
+				})).Return(nil)
+				todoCache.On("SetIfNewer", mock.Anything, cachedItem(testCase.input.Id), mock.Anything).Return(nil)
+				todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(testCase.cacheSetReturn)
 			}
 
-			todoService := service.NewTodoService(todoRepo, todoCache)
+			todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
 
 			// Act
-			err := todoService.Create(testCase.input)
+			created, err := todoService.Create(context.Background(), testCase.input)
 
 			// Assert
 			if testCase.expectedErr != nil {
@@ -314,138 +434,86 @@ func TestTodoserviceCreateTodo(t *testing.T) {
 				assert.Equal(t, testCase.expectedErr, err)
 			} else {
 				assert.NoError(t, err)
+				assert.True(t, stampedFrom(testCase.input)(created))
 			}
 
 			todoRepo.AssertExpectations(t)
+			todoCache.AssertExpectations(t)
+			todoHistory.AssertExpectations(t)
+			todoOutbox.AssertExpectations(t)
 		})
 	}
 }
 
+// stampedFrom matches input once the service has set its timestamps, position and version on create.
+func stampedFrom(input dto.Todo) func(dto.Todo) bool {
+	return func(todo dto.Todo) bool {
+		completed := todo.CompletedAt != nil && todo.CompletedAt.Equal(todo.CreatedAt)
+		stamped := !todo.CreatedAt.IsZero() && todo.UpdatedAt.Equal(todo.CreatedAt) && completed == (todo.Status == dto.TodoStatusDone)
+		positioned := todo.Position != "" && todo.Version == 1
+		todo.CreatedAt, todo.UpdatedAt, todo.CompletedAt, todo.Position, todo.Version = time.Time{}, time.Time{}, nil, "", input.Version
+		return stamped && positioned && reflect.DeepEqual(todo, input)
+	}
+}
+
 func TestTodoserviceUpdateStatus(t *testing.T) {
 	testCases := []struct {
-		description       string
-		input             dto.TodoInputUpdateStatus
-		repoUpdateReturn  error
-		repoFindAllReturn struct {
-			todos []dto.Todo
-			err   error
-		}
-		cacheGetReturn struct {
-			data string
-			err  error
-		}
-		cacheSetReturn error
-		expectedErr    error
+		description      string
+		input            dto.TodoInputUpdateStatus
+		repoUpdateReturn error
+		cacheSetReturn   error
+		expectedErr      error
 	}{
 		{
 			description: "Update status success.",
 			input: dto.TodoInputUpdateStatus{
 				Id:     "1",
-				Status: "Pending",
+				Status: "reopened",
 			},
 			repoUpdateReturn: nil,
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{
-				todos: []dto.Todo{
-					{
-						Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
-						Topic:       "Complete Project",
-						Description: "Description for Complete Project",
-						Status:      "Completed",
-					},
-				},
-				err: nil,
-			},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{
-				data: "[{\"id\":\"1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412\",\"topic\":\"Complete Project\",\"description\":\"Description for Complete Project\",\"status\":\"Completed\"}]",
-				err:  nil,
+			cacheSetReturn:   nil,
+			expectedErr:      nil,
+		},
+		{
+			description: "Update status is failed repository update dont have Id.",
+			input: dto.TodoInputUpdateStatus{
+				Id:     "not",
+				Status: "done",
 			},
-			cacheSetReturn: nil,
-			expectedErr:    nil,
+			repoUpdateReturn: errors.New("error update"),
+			cacheSetReturn:   nil,
+			expectedErr:      errors.New("error update"),
 		},
 		{
-			description: "Update status is miss cache.",
+			description: "Update status succeeds when cache invalidation fails.",
 			input: dto.TodoInputUpdateStatus{
 				Id:     "1",
-				Status: "Pending",
+				Status: "reopened",
 			},
 			repoUpdateReturn: nil,
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{
-				todos: []dto.Todo{
-					{
-						Id:          "1e89f1d7-78c5-4d4a-bae3-d4f5f96a7412",
-						Topic:       "Complete Project",
-						Description: "Description for Complete Project",
-						Status:      "Completed",
-					},
-				},
-				err: nil,
-			},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{
-				data: "",
-				err:  errors.New("miss cache"),
-			},
-			cacheSetReturn: nil,
-			expectedErr:    nil,
+			cacheSetReturn:   errors.New("error set cache"),
+			expectedErr:      nil,
 		},
 		{
-			description: "Update status is failed repository update dont have Id.",
+			description: "Update status is failed todo not found.",
 			input: dto.TodoInputUpdateStatus{
 				Id:     "not",
-				Status: "Complete",
-			},
-			repoUpdateReturn: errors.New("error update"),
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{
-				todos: []dto.Todo{},
-				err:   nil,
-			},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{
-				data: "",
-				err:  nil,
+				Status: "done",
 			},
-			cacheSetReturn: nil,
-			expectedErr:    errors.New("error update"),
+			repoUpdateReturn: dto.ErrTodoNotFound,
+			cacheSetReturn:   nil,
+			expectedErr:      dto.ErrTodoNotFound,
 		},
 		{
-			description: "Update status is failed repository findall.",
+			description: "Update status is failed stale version.",
 			input: dto.TodoInputUpdateStatus{
-				Id:     "1",
-				Status: "Pending",
-			},
-			repoUpdateReturn: nil,
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{
-				todos: []dto.Todo{},
-				err:   errors.New("error findall"),
-			},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{
-				data: "",
-				err:  errors.New("miss cache."),
+				Id:              "1",
+				Status:          "done",
+				ExpectedVersion: 1,
 			},
-			cacheSetReturn: nil,
-			expectedErr:    errors.New("error findall"),
+			repoUpdateReturn: dto.ErrVersionConflict,
+			cacheSetReturn:   nil,
+			expectedErr:      dto.ErrVersionConflict,
 		},
 	}
 
@@ -454,22 +522,29 @@ func TestTodoserviceUpdateStatus(t *testing.T) {
 			// Arrange
 			todoRepo := repository.NewTodoRepositoryMock()
 			todoCache := cache.NewRedisCacheMock()
+			todoHistory := repository.NewTodoHistoryRepositoryMock()
+			todoOutbox := repository.NewOutboxRepositoryMock()
 
-			todoRepo.On("Update", testCase.input).Return(testCase.repoUpdateReturn)
+			before := dto.Todo{Id: testCase.input.Id, Status: "done", Version: 1}
+			updated := dto.Todo{Id: testCase.input.Id, Status: testCase.input.Status, Version: 2}
+			todoRepo.On("FindSubtree", mock.Anything, testCase.input.Id).Return([]dto.Todo{}, nil).Maybe()
+			todoRepo.On("FindByIdForUpdate", mock.Anything, testCase.input.Id).Return(before, nil)
+			todoRepo.On("Update", mock.Anything, testCase.input).Return(updated, testCase.repoUpdateReturn)
 			if testCase.repoUpdateReturn == nil {
-
-				todoCache.On("Get", mock.Anything, "todos").Return(testCase.cacheGetReturn.data, testCase.cacheGetReturn.err)
-
-				if testCase.cacheGetReturn.err != nil {
-					todoRepo.On("FindAll").Return(testCase.repoFindAllReturn.todos, testCase.repoFindAllReturn.err)
-				}
-				todoCache.On("Set", mock.Anything, "todos", mock.Anything, mock.Anything).Return(testCase.cacheSetReturn)
+				todoHistory.On("Append", mock.Anything, mock.MatchedBy(func(entry dto.TodoHistory) bool {
+					return entry.Action == dto.TodoActionUpdated && reflect.DeepEqual(*entry.Before, before) && reflect.DeepEqual(*entry.After, updated)
+				})).Return(nil)
+				todoOutbox.On("Append", mock.Anything, mock.MatchedBy(func(event dto.OutboxEvent) bool {
+					return event.EventType == dto.EventTodoStatusChanged && event.AggregateId == testCase.input.Id
+				})).Return(nil)
+				todoCache.On("SetIfNewer", mock.Anything, cachedItem(testCase.input.Id), mock.Anything).Return(nil)
+				todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(testCase.cacheSetReturn)
 			}
 
-			todoService := service.NewTodoService(todoRepo, todoCache)
+			todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
 
 			// Act
-			err := todoService.Update(testCase.input)
+			response, err := todoService.Update(context.Background(), testCase.input)
 
 			// Assert
 			if testCase.expectedErr != nil {
@@ -477,28 +552,44 @@ func TestTodoserviceUpdateStatus(t *testing.T) {
 				assert.Equal(t, testCase.expectedErr, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, updated, response)
 			}
 
 			todoRepo.AssertExpectations(t)
+			todoCache.AssertExpectations(t)
+			todoHistory.AssertExpectations(t)
+			todoOutbox.AssertExpectations(t)
 		})
 	}
 }
 
+func TestTodoserviceUpdateIllegalTransition(t *testing.T) {
+	// Arrange
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+	todoRepo.On("FindByIdForUpdate", mock.Anything, "1").Return(dto.Todo{Id: "1", Status: dto.TodoStatusDone, Version: 1}, nil)
+
+	todoService := service.NewTodoService(todoRepo, repository.NewTodoHistoryRepositoryMock(), repository.NewOutboxRepositoryMock(), repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+
+	// Act
+	_, err := todoService.Update(context.Background(), dto.TodoInputUpdateStatus{Id: "1", Status: dto.TodoStatusInProgress})
+
+	// Assert
+	var transition *dto.StatusTransitionError
+	assert.ErrorIs(t, err, dto.ErrIllegalTransition)
+	assert.ErrorAs(t, err, &transition)
+	assert.Equal(t, []dto.TodoStatus{dto.TodoStatusReopened}, transition.Allowed)
+	todoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	todoCache.AssertExpectations(t)
+}
+
 func TestTodoserviceDelete(t *testing.T) {
 	testCases := []struct {
-		description       string
-		input             dto.TodoInputDelete
-		repoDeleteReturn  error
-		repoFindAllReturn struct {
-			todos []dto.Todo
-			err   error
-		}
-		cacheGetReturn struct {
-			data string
-			err  error
-		}
-		cacheSetReturn error
-		expectedErr    error
+		description      string
+		input            dto.TodoInputDelete
+		repoDeleteReturn error
+		cacheSetReturn   error
+		expectedErr      error
 	}{
 		{
 			description: "Delete success",
@@ -506,19 +597,8 @@ func TestTodoserviceDelete(t *testing.T) {
 				Id: "1",
 			},
 			repoDeleteReturn: nil,
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{[]dto.Todo{}, nil},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{
-				data: "[{\"id\":\"1\",\"topic\":\"Complete Project\",\"description\":\"Description for Complete Project\",\"status\":\"Completed\"}]",
-				err:  nil,
-			},
-			cacheSetReturn: nil,
-			expectedErr:    nil,
+			cacheSetReturn:   nil,
+			expectedErr:      nil,
 		},
 		{
 			description: "failed repository Delete.",
@@ -526,61 +606,26 @@ func TestTodoserviceDelete(t *testing.T) {
 				Id: "1",
 			},
 			repoDeleteReturn: errors.New("failed repository delete."),
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{[]dto.Todo{}, nil},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{data: "", err: nil},
-			cacheSetReturn: nil,
-			expectedErr:    errors.New("failed repository delete."),
+			cacheSetReturn:   nil,
+			expectedErr:      errors.New("failed repository delete."),
 		},
 		{
-			description: "Cache hit",
+			description: "Todo not found.",
 			input: dto.TodoInputDelete{
-				Id: "1",
+				Id: "not",
 			},
-			repoDeleteReturn: nil,
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{[]dto.Todo{}, nil},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{
-				data: "[{\"id\":\"1\",\"topic\":\"Complete Project\",\"description\":\"Description for Complete Project\",\"status\":\"Completed\"}]",
-				err:  nil,
-			},
-			cacheSetReturn: nil,
-			expectedErr:    nil,
+			repoDeleteReturn: dto.ErrTodoNotFound,
+			cacheSetReturn:   nil,
+			expectedErr:      dto.ErrTodoNotFound,
 		},
 		{
-			description: "Cache miss.",
+			description: "Delete succeeds when cache invalidation fails.",
 			input: dto.TodoInputDelete{
 				Id: "1",
 			},
 			repoDeleteReturn: nil,
-			repoFindAllReturn: struct {
-				todos []dto.Todo
-				err   error
-			}{
-				[]dto.Todo{
-					{
-						Id:          "1",
-						Topic:       "Complete Project",
-						Description: "Description for Complete Project",
-						Status:      "Completed",
-					},
-				}, nil},
-			cacheGetReturn: struct {
-				data string
-				err  error
-			}{data: "", err: errors.New("cache miss")},
-			cacheSetReturn: nil,
-			expectedErr:    nil,
+			cacheSetReturn:   errors.New("failed set cache."),
+			expectedErr:      nil,
 		},
 	}
 
@@ -589,21 +634,24 @@ func TestTodoserviceDelete(t *testing.T) {
 			// Arrange
 			todoRepo := repository.NewTodoRepositoryMock()
 			todoCache := cache.NewRedisCacheMock()
+			todoHistory := repository.NewTodoHistoryRepositoryMock()
+			todoOutbox := repository.NewOutboxRepositoryMock()
 
-			todoRepo.On("Delete", testCase.input).Return(testCase.repoDeleteReturn)
-
+			before := dto.Todo{Id: testCase.input.Id, Version: 1}
+			todoRepo.On("FindSubtree", mock.Anything, testCase.input.Id).Return([]dto.Todo{}, nil)
+			todoRepo.On("FindByIdForUpdate", mock.Anything, testCase.input.Id).Return(before, nil)
+			todoRepo.On("Delete", mock.Anything, testCase.input).Return(dto.Todo{Id: testCase.input.Id, Version: 2}, testCase.repoDeleteReturn)
 			if testCase.repoDeleteReturn == nil {
-				todoCache.On("Get", mock.Anything, "todos").Return(testCase.cacheGetReturn.data, testCase.cacheGetReturn.err)
-				if testCase.cacheGetReturn.err != nil {
-					todoRepo.On("FindAll").Return(testCase.repoFindAllReturn.todos, testCase.repoFindAllReturn.err)
-				}
-				todoCache.On("Set", mock.Anything, "todos", mock.Anything, mock.Anything).Return(testCase.cacheSetReturn)
+				todoHistory.On("Append", mock.Anything, mock.Anything).Return(nil)
+				todoOutbox.On("Append", mock.Anything, mock.Anything).Return(nil)
+				todoCache.On("SetIfNewer", mock.Anything, cachedItem(testCase.input.Id), mock.Anything).Return(nil)
+				todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(testCase.cacheSetReturn)
 			}
 
-			todoService := service.NewTodoService(todoRepo, todoCache)
+			todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
 
 			// Act
-			err := todoService.Delete(testCase.input)
+			err := todoService.Delete(context.Background(), testCase.input)
 
 			// Assert
 			if testCase.expectedErr != nil {
@@ -614,6 +662,404 @@ func TestTodoserviceDelete(t *testing.T) {
 			}
 
 			todoRepo.AssertExpectations(t)
+			todoCache.AssertExpectations(t)
+			todoHistory.AssertExpectations(t)
+			todoOutbox.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTodoserviceRestore(t *testing.T) {
+	restored := dto.Todo{
+		Id:          "1",
+		Topic:       "Complete Project",
+		Description: "Description for Complete Project",
+		Status:      "done",
+		Version:     3,
+	}
+
+	testCases := []struct {
+		description      string
+		repoRestoreError error
+		expectedErr      error
+	}{
+		{
+			description:      "Restore invalidates cached lists",
+			repoRestoreError: nil,
+			expectedErr:      nil,
+		},
+		{
+			description:      "Restore unknown todo",
+			repoRestoreError: dto.ErrTodoNotFound,
+			expectedErr:      dto.ErrTodoNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			todoRepo := repository.NewTodoRepositoryMock()
+			todoCache := cache.NewRedisCacheMock()
+			todoHistory := repository.NewTodoHistoryRepositoryMock()
+			todoOutbox := repository.NewOutboxRepositoryMock()
+
+			todoRepo.On("FindByIdForUpdate", mock.Anything, "1").Return(restored, nil)
+			todoRepo.On("Restore", mock.Anything, "1").Return(restored, testCase.repoRestoreError)
+			if testCase.repoRestoreError == nil {
+				todoHistory.On("Append", mock.Anything, mock.Anything).Return(nil)
+				todoOutbox.On("Append", mock.Anything, mock.Anything).Return(nil)
+				todoCache.On("SetIfNewer", mock.Anything, cachedItem("1"), mock.Anything).Return(nil)
+				todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+
+			// Act
+			response, err := todoService.Restore(context.Background(), "1")
+
+			// Assert
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, restored, response)
+			}
+			todoRepo.AssertExpectations(t)
+			todoCache.AssertExpectations(t)
+			todoHistory.AssertExpectations(t)
+			todoOutbox.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTodoserviceSearch(t *testing.T) {
+	results := []dto.TodoSearchResult{
+		{
+			Todo:         dto.Todo{Id: "1", Topic: "Complete Project"},
+			Rank:         0.6,
+			TopicSnippet: "Complete <mark>Project</mark>",
+		},
+	}
+
+	testCases := []struct {
+		description string
+		query       dto.TodoSearchQuery
+		expected    []dto.TodoSearchResult
+		expectedErr error
+	}{
+		{
+			description: "Search passes normalized query to repository",
+			query:       dto.TodoSearchQuery{Text: "  project "},
+			expected:    results,
+			expectedErr: nil,
+		},
+		{
+			description: "Search rejects empty text",
+			query:       dto.TodoSearchQuery{Text: "   "},
+			expectedErr: dto.ErrInvalidQuery,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			todoRepo := repository.NewTodoRepositoryMock()
+			todoCache := cache.NewRedisCacheMock()
+			todoHistory := repository.NewTodoHistoryRepositoryMock()
+			todoOutbox := repository.NewOutboxRepositoryMock()
+
+			if testCase.expectedErr == nil {
+				todoRepo.On("Search", mock.Anything, dto.TodoSearchQuery{Text: "project", Limit: dto.DefaultTodoLimit}).Return(results, nil)
+			}
+
+			todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+
+			// Act
+			response, err := todoService.Search(context.Background(), testCase.query)
+
+			// Assert
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expected, response)
+			}
+			todoRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTodoserviceHistory(t *testing.T) {
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+	todoHistory := repository.NewTodoHistoryRepositoryMock()
+	todoOutbox := repository.NewOutboxRepositoryMock()
+
+	input := dto.Todo{Id: "1", Topic: "Complete Project", Status: "pending", Version: 1}
+	todoRepo.On("LastPosition", mock.Anything).Return("", nil)
+	todoRepo.On("Save", mock.Anything, mock.MatchedBy(stampedFrom(input))).Return(nil)
+	todoOutbox.On("Append", mock.Anything, mock.Anything).Return(nil)
+	todoCache.On("SetIfNewer", mock.Anything, cachedItem("1"), mock.Anything).Return(nil)
+	todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(nil)
+
+	var recorded dto.TodoHistory
+	todoHistory.On("Append", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(dto.TodoHistory)
+	})
+	todoHistory.On("FindByTodoId", mock.Anything, "1").Return([]dto.TodoHistory{recorded}, nil)
+	todoHistory.On("FindByTodoId", mock.Anything, "unknown").Return([]dto.TodoHistory{}, nil)
+
+	todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+	ctx := requestctx.WithActor(requestctx.WithRequestId(context.Background(), "req-1"), "alice")
+
+	_, err := todoService.Create(ctx, input)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", recorded.TodoId)
+	assert.Equal(t, dto.TodoActionCreated, recorded.Action)
+	assert.Equal(t, "alice", recorded.Actor)
+	assert.Equal(t, "req-1", recorded.RequestId)
+	assert.False(t, recorded.CreatedAt.IsZero())
+
+	entries, err := todoService.History(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	_, err = todoService.History(context.Background(), "unknown")
+	assert.Equal(t, dto.ErrTodoNotFound, err)
+}
+
+func TestTodoserviceMove(t *testing.T) {
+	high := dto.TodoPriorityHigh
+	none := dto.TodoPriorityNone
+
+	testCases := []struct {
+		description   string
+		input         dto.TodoInputMove
+		expectedOrder []string
+		expectedErr   error
+	}{
+		{
+			description:   "Move after a todo of another priority takes that priority.",
+			input:         dto.TodoInputMove{Id: "c", After: "a"},
+			expectedOrder: []string{"a", "c", "b"},
+		},
+		{
+			description:   "Move before a todo places it ahead of it within the priority.",
+			input:         dto.TodoInputMove{Id: "c", Before: "b"},
+			expectedOrder: []string{"a", "c", "b"},
+		},
+		{
+			description:   "Move with only a priority places it at the end of that priority.",
+			input:         dto.TodoInputMove{Id: "b", Priority: &high},
+			expectedOrder: []string{"a", "b", "c"},
+		},
+		{
+			description: "Move between todos of two priorities is rejected.",
+			input:       dto.TodoInputMove{Id: "c", After: "a", Before: "b"},
+			expectedErr: dto.ErrInvalidMove,
+		},
+		{
+			description: "Move with a priority other than its neighbour's is rejected.",
+			input:       dto.TodoInputMove{Id: "c", After: "a", Priority: &none},
+			expectedErr: dto.ErrInvalidMove,
+		},
+		{
+			description: "Move next to itself is rejected.",
+			input:       dto.TodoInputMove{Id: "c", After: "c"},
+			expectedErr: dto.ErrInvalidMove,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			todoService := service.NewTodoService(memory.NewMemoryTodoRepository(), memory.NewMemoryTodoHistoryRepository(), memory.NewMemoryOutboxRepository(), memory.NewMemoryTransactor(), memory.NewMemoryCache(), serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+			for _, todo := range []dto.Todo{
+				{Id: "a", Status: dto.TodoStatusPending, Priority: dto.TodoPriorityHigh, Version: 1},
+				{Id: "b", Status: dto.TodoStatusPending, Version: 1},
+				{Id: "c", Status: dto.TodoStatusPending, Version: 1},
+			} {
+				_, err := todoService.Create(ctx, todo)
+				assert.NoError(t, err)
+			}
+
+			// Act
+			moved, err := todoService.Move(ctx, testCase.input)
+
+			// Assert
+			assert.Equal(t, testCase.expectedErr, err)
+			if testCase.expectedErr != nil {
+				return
+			}
+			page, err := todoService.FindAll(ctx, dto.TodoQuery{})
+			assert.NoError(t, err)
+			order := make([]string, 0, len(page.Todos))
+			for _, todo := range page.Todos {
+				order = append(order, todo.Id)
+			}
+			assert.Equal(t, testCase.expectedOrder, order)
+			assert.Equal(t, int64(2), moved.Version)
+		})
+	}
+}
+
+func TestTodoserviceSchedule(t *testing.T) {
+	due := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+
+	testCases := []struct {
+		description       string
+		input             dto.TodoInputSchedule
+		expectedReminders dto.ReminderOffsets
+		expectedErr       error
+	}{
+		{
+			description:       "Schedule sets the due date with its reminders sorted and deduplicated.",
+			input:             dto.TodoInputSchedule{Id: "1", Due: dto.DueDate{At: &due}, Reminders: dto.ReminderOffsets{time.Hour, 24 * time.Hour, time.Hour}},
+			expectedReminders: dto.ReminderOffsets{24 * time.Hour, time.Hour},
+		},
+		{
+			description: "Schedule clears the due date along with the reminders.",
+			input:       dto.TodoInputSchedule{Id: "1"},
+		},
+		{
+			description: "Schedule rejects reminders without a due date.",
+			input:       dto.TodoInputSchedule{Id: "1", Reminders: dto.ReminderOffsets{time.Hour}},
+			expectedErr: dto.ErrInvalidDue,
+		},
+		{
+			description: "Schedule rejects a reminder that is not before the due date.",
+			input:       dto.TodoInputSchedule{Id: "1", Due: dto.DueDate{At: &due}, Reminders: dto.ReminderOffsets{-time.Hour}},
+			expectedErr: dto.ErrInvalidDue,
+		},
+		{
+			description: "Schedule rejects a stale version.",
+			input:       dto.TodoInputSchedule{Id: "1", Due: dto.DueDate{At: &due}, ExpectedVersion: 2},
+			expectedErr: dto.ErrVersionConflict,
+		},
+		{
+			description: "Schedule reports a missing todo.",
+			input:       dto.TodoInputSchedule{Id: "2", Due: dto.DueDate{At: &due}},
+			expectedErr: dto.ErrTodoNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			todoService := service.NewTodoService(memory.NewMemoryTodoRepository(), memory.NewMemoryTodoHistoryRepository(), memory.NewMemoryOutboxRepository(), memory.NewMemoryTransactor(), memory.NewMemoryCache(), serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+			_, err := todoService.Create(ctx, dto.Todo{Id: "1", Status: dto.TodoStatusPending, Version: 1})
+			assert.NoError(t, err)
+
+			// Act
+			response, err := todoService.Schedule(ctx, testCase.input)
+
+			// Assert
+			assert.Equal(t, testCase.expectedErr, err)
+			if testCase.expectedErr == nil {
+				assert.Equal(t, testCase.input.Due, response.Due)
+				assert.Equal(t, testCase.expectedReminders, response.Reminders)
+				assert.Equal(t, int64(2), response.Version)
+			}
+		})
+	}
+}
+
+func TestTodoserviceDue(t *testing.T) {
+	now := time.Now()
+	at := func(offset time.Duration) dto.DueDate {
+		due := now.Add(offset)
+		return dto.DueDate{At: &due}
+	}
+	overdue := func(todoService service.TodoService, limit int) ([]dto.Todo, error) {
+		return todoService.Overdue(context.Background(), limit)
+	}
+	dueWithin := func(within time.Duration) func(service.TodoService, int) ([]dto.Todo, error) {
+		return func(todoService service.TodoService, limit int) ([]dto.Todo, error) {
+			return todoService.DueWithin(context.Background(), within, limit)
+		}
+	}
+
+	testCases := []struct {
+		description string
+		list        func(todoService service.TodoService, limit int) ([]dto.Todo, error)
+		limit       int
+		expected    []string
+		expectedErr error
+	}{
+		{
+			description: "Overdue lists open todos past their due date, the longest overdue first.",
+			list:        overdue,
+			expected:    []string{"overdue-2d", "overdue-1h"},
+		},
+		{
+			description: "Overdue stops at the limit.",
+			list:        overdue,
+			limit:       1,
+			expected:    []string{"overdue-2d"},
+		},
+		{
+			description: "Overdue rejects a limit above the maximum.",
+			list:        overdue,
+			limit:       dto.MaxTodoLimit + 1,
+			expectedErr: dto.ErrInvalidQuery,
+		},
+		{
+			description: "DueWithin lists open todos due in the window, the soonest first.",
+			list:        dueWithin(24 * time.Hour),
+			expected:    []string{"due-1h", "due-3h"},
+		},
+		{
+			description: "DueWithin leaves out todos due after the window.",
+			list:        dueWithin(2 * time.Hour),
+			expected:    []string{"due-1h"},
+		},
+		{
+			description: "DueWithin rejects an empty window.",
+			list:        dueWithin(0),
+			expectedErr: dto.ErrInvalidQuery,
+		},
+		{
+			description: "DueWithin rejects a negative limit.",
+			list:        dueWithin(time.Hour),
+			limit:       -1,
+			expectedErr: dto.ErrInvalidQuery,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			todoService := service.NewTodoService(memory.NewMemoryTodoRepository(), memory.NewMemoryTodoHistoryRepository(), memory.NewMemoryOutboxRepository(), memory.NewMemoryTransactor(), memory.NewMemoryCache(), serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{}, service.SubtaskPolicy{})
+			for id, due := range map[string]dto.DueDate{
+				"overdue-1h": at(-time.Hour),
+				"overdue-2d": at(-48 * time.Hour),
+				"done-1h":    at(-time.Hour),
+				"due-3h":     at(3 * time.Hour),
+				"due-1h":     at(time.Hour),
+				"due-3d":     at(72 * time.Hour),
+				"undated":    {},
+			} {
+				_, err := todoService.Create(ctx, dto.Todo{Id: id, Status: dto.TodoStatusPending, Version: 1})
+				assert.NoError(t, err)
+				_, err = todoService.Schedule(ctx, dto.TodoInputSchedule{Id: id, Due: due})
+				assert.NoError(t, err)
+			}
+			_, err := todoService.Update(ctx, dto.TodoInputUpdateStatus{Id: "done-1h", Status: dto.TodoStatusDone})
+			assert.NoError(t, err)
+
+			// Act
+			todos, err := testCase.list(todoService, testCase.limit)
+
+			// Assert
+			assert.Equal(t, testCase.expectedErr, err)
+			var ids []string
+			for _, todo := range todos {
+				ids = append(ids, todo.Id)
+			}
+			assert.Equal(t, testCase.expected, ids)
 		})
 	}
 }
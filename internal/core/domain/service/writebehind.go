@@ -0,0 +1,160 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+type WriteBehindFlusher interface {
+	Flush(ctx context.Context, batchSize int) (int, error)
+}
+
+type writeBehindFlusherImpl struct {
+	repo       repository.TodoRepository
+	history    repository.TodoHistoryRepository
+	outbox     repository.OutboxRepository
+	transactor repository.Transactor
+	buffer     cache.WriteBuffer
+	cache      cache.Cache
+	codec      codec.Codec
+	keys       todoKeys
+	ttl        CacheTTL
+}
+
+func NewWriteBehindFlusher(repo repository.TodoRepository, history repository.TodoHistoryRepository, outbox repository.OutboxRepository, transactor repository.Transactor, buffer cache.WriteBuffer, cache cache.Cache, codec codec.Codec, schema string, ttl CacheTTL) WriteBehindFlusher {
+	if ttl.Item <= 0 {
+		ttl.Item = defaultItemTTL
+	}
+	return &writeBehindFlusherImpl{
+		repo:       repo,
+		history:    history,
+		outbox:     outbox,
+		transactor: transactor,
+		buffer:     buffer,
+		cache:      cache,
+		codec:      codec,
+		keys:       newTodoKeys(codec, schema),
+		ttl:        ttl,
+	}
+}
+
+// errWriteAhead marks a buffered write whose todo is still missing an earlier version.
+var errWriteAhead = errors.New("buffered write is ahead of the repository")
+
+// Flush stores up to batchSize buffered writes in the repository, each in its own transaction with
+// its audit entry and event, and acks it once committed. The writes to one todo are stored in
+// version order: a write whose earlier version is not stored yet, because it failed or is claimed
+// elsewhere, is left with the later writes to that todo to be claimed again after the claim
+// timeout. A write the repository already stored, with its audit entry and event, is only acked,
+// which makes redelivery harmless. Flush returns the first failure after trying every todo.
+func (f *writeBehindFlusherImpl) Flush(ctx context.Context, batchSize int) (int, error) {
+	writes, err := f.buffer.Claim(ctx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	slices.SortStableFunc(writes, func(a, b dto.BufferedWrite) int {
+		return cmp.Compare(a.Todo.Version, b.Todo.Version)
+	})
+
+	flushed := 0
+	held := map[string]bool{}
+	var failure error
+	for _, write := range writes {
+		if held[write.Todo.Id] {
+			continue
+		}
+		err := f.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return f.store(ctx, write)
+		})
+		if err == nil {
+			err = f.buffer.Ack(ctx, write.Id)
+		}
+		if err != nil {
+			held[write.Todo.Id] = true
+			if !errors.Is(err, errWriteAhead) && failure == nil {
+				failure = err
+			}
+			continue
+		}
+		f.settle(ctx, write.Todo.Id)
+		flushed++
+	}
+
+	if flushed > 0 {
+		if err := invalidateLists(ctx, f.cache, f.keys); err != nil {
+			cacheFailed(ctx, "set", f.keys.generation(), err)
+		}
+	}
+	return flushed, failure
+}
+
+// store writes one buffered write with its audit entry and event when it is the todo's next
+// version. A write at a version already stored is done when that version came from the same write,
+// told by its audit entry; otherwise it lost a race with another instance's write to the same
+// version and is logged in full for an operator before it is acked.
+func (f *writeBehindFlusherImpl) store(ctx context.Context, write dto.BufferedWrite) error {
+	stored, err := f.repo.WriteNext(ctx, write.Todo)
+	switch {
+	case err != nil:
+		return err
+	case stored < write.Todo.Version-1:
+		return errWriteAhead
+	case stored >= write.Todo.Version:
+		entries, err := f.history.FindByTodoId(ctx, write.Todo.Id)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(entries, func(entry dto.TodoHistory) bool {
+			return entry.After != nil && entry.After.Version == write.Todo.Version && entry.After.UpdatedAt.Equal(write.Todo.UpdatedAt)
+		}) {
+			logger.Log.Error("Dropping buffered write that lost to another write of the same version.",
+				zap.String("id", write.Id),
+				zap.Any("write", write),
+			)
+		}
+		return nil
+	}
+
+	if err := f.history.Append(ctx, write.History); err != nil {
+		return err
+	}
+	if write.Event == nil {
+		return nil
+	}
+	return f.outbox.Append(ctx, *write.Event)
+}
+
+// settle replaces the cached todo, held without a TTL while its write was pending, with what the
+// repository now has. A newer pending write keeps its entry, since SetIfNewer never lowers a version.
+func (f *writeBehindFlusherImpl) settle(ctx context.Context, id string) {
+	key := f.keys.item(id)
+	todo, err := f.repo.FindByIdForUpdate(ctx, id)
+	if errors.Is(err, dto.ErrTodoNotFound) {
+		if err := f.cache.Del(ctx, key); err != nil {
+			cacheFailed(ctx, "delete", key, err)
+		}
+		return
+	}
+	if err != nil {
+		cacheFailed(ctx, "settle", key, err)
+		return
+	}
+
+	value, err := encodeTodo(f.codec, todo)
+	if err != nil {
+		cacheFailed(ctx, "encode", key, err)
+		return
+	}
+	if err := f.cache.SetIfNewer(ctx, map[string]string{key: value}, f.ttl.Item); err != nil {
+		cacheFailed(ctx, "set", key, err)
+	}
+}
@@ -0,0 +1,297 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/memory"
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/serialization"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTodoserviceUpdateInvalidates(t *testing.T) {
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+	todoHistory := repository.NewTodoHistoryRepositoryMock()
+	todoOutbox := repository.NewOutboxRepositoryMock()
+
+	input := dto.TodoInputUpdateStatus{Id: "1", Status: "pending"}
+	updated := dto.Todo{Id: "1", Status: "pending", Version: 2}
+	todoRepo.On("FindByIdForUpdate", mock.Anything, "1").Return(dto.Todo{Id: "1", Status: "pending", Version: 1}, nil)
+	todoRepo.On("Update", mock.Anything, input).Return(updated, nil)
+	todoHistory.On("Append", mock.Anything, mock.Anything).Return(nil)
+	todoCache.On("Del", mock.Anything, []string{"todos:json:v1:item:1"}).Return(nil)
+	todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(nil)
+
+	todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{Strategy: service.CacheWriteInvalidate}, service.SubtaskPolicy{})
+
+	response, err := todoService.Update(context.Background(), input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, updated, response)
+	todoRepo.AssertExpectations(t)
+	todoCache.AssertExpectations(t)
+	todoOutbox.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+}
+
+func TestTodoserviceUpdateWritesBehind(t *testing.T) {
+	testCases := []struct {
+		description     string
+		expectedVersion int64
+		cacheErr        error
+		bufferErr       error
+		expectedErr     error
+	}{
+		{description: "Update is buffered and cached."},
+		{description: "Update is rejected on a stale version.", expectedVersion: 1, expectedErr: dto.ErrVersionConflict},
+		{description: "Update goes through when the cache write fails.", cacheErr: errors.New("cache unavailable")},
+		{description: "Update goes through when the buffer fails.", bufferErr: errors.New("buffer unavailable")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			todoRepo := repository.NewTodoRepositoryMock()
+			todoCache := cache.NewRedisCacheMock()
+			todoHistory := repository.NewTodoHistoryRepositoryMock()
+			todoOutbox := repository.NewOutboxRepositoryMock()
+			buffer := cache.NewWriteBufferMock()
+
+			input := dto.TodoInputUpdateStatus{Id: "1", Status: "reopened", ExpectedVersion: testCase.expectedVersion}
+			updated := dto.Todo{Id: "1", Status: "reopened", Version: 3}
+			// A buffered update carries the timestamps the repository would have set.
+			stamped := func(todo dto.Todo) bool {
+				ok := !todo.UpdatedAt.IsZero()
+				todo.CreatedAt, todo.UpdatedAt = time.Time{}, time.Time{}
+				return ok && reflect.DeepEqual(todo, updated)
+			}
+			// The cache holds version 2, written behind and not flushed yet.
+			todoCache.On("Get", mock.Anything, "todos:json:v1:item:1").Return(`2:{"id":"1","status":"done","version":2}`, nil)
+			if testCase.expectedErr == nil {
+				// Pending writes stay cached until the flusher has stored them.
+				todoCache.On("SetIfNewer", mock.Anything, cachedItem("1"), time.Duration(0)).Return(testCase.cacheErr)
+				todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(nil)
+			}
+			if testCase.expectedErr == nil && testCase.cacheErr == nil {
+				buffer.On("Append", mock.Anything, mock.MatchedBy(func(write dto.BufferedWrite) bool {
+					return stamped(write.Todo) && write.History.Action == dto.TodoActionUpdated &&
+						write.Event != nil && write.Event.EventType == dto.EventTodoStatusChanged
+				})).Return(testCase.bufferErr)
+			}
+			if testCase.bufferErr != nil {
+				todoCache.On("Del", mock.Anything, []string{"todos:json:v1:item:1"}).Return(nil)
+			}
+			if testCase.cacheErr != nil || testCase.bufferErr != nil {
+				todoCache.On("SetIfNewer", mock.Anything, cachedItem("1"), 5*time.Minute).Return(nil)
+				todoRepo.On("FindByIdForUpdate", mock.Anything, "1").Return(dto.Todo{Id: "1", Status: "done", Version: 2}, nil)
+				todoRepo.On("Update", mock.Anything, input).Return(updated, nil)
+				todoHistory.On("Append", mock.Anything, mock.Anything).Return(nil)
+				todoOutbox.On("Append", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			todoService := service.NewTodoService(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{Strategy: service.CacheWriteBehind, Buffer: buffer}, service.SubtaskPolicy{})
+
+			// Act
+			response, err := todoService.Update(context.Background(), input)
+
+			// Assert
+			switch {
+			case testCase.expectedErr != nil:
+				assert.Equal(t, testCase.expectedErr, err)
+			case testCase.cacheErr != nil || testCase.bufferErr != nil:
+				assert.NoError(t, err)
+				assert.Equal(t, updated, response)
+			default:
+				assert.NoError(t, err)
+				assert.True(t, stamped(response))
+			}
+			todoRepo.AssertExpectations(t)
+			todoCache.AssertExpectations(t)
+			buffer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTodoserviceCreateWritesBehindInOrder(t *testing.T) {
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+	buffer := cache.NewWriteBufferMock()
+
+	// Neither create is flushed, so the repository reports the same last position both times.
+	todoRepo.On("LastPosition", mock.Anything).Return("i", nil)
+	var positions []string
+	buffer.On("Append", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		write := args.Get(1).(dto.BufferedWrite)
+		// The flusher only inserts a missing todo at version 1.
+		assert.Equal(t, int64(1), write.Todo.Version)
+		positions = append(positions, write.Todo.Position)
+	})
+	todoCache.On("SetIfNewer", mock.Anything, mock.Anything, time.Duration(0)).Return(nil)
+	todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(nil)
+
+	todoService := service.NewTodoService(todoRepo, repository.NewTodoHistoryRepositoryMock(), repository.NewOutboxRepositoryMock(), repository.NewTransactorMock(), todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{}, service.CacheWrite{Strategy: service.CacheWriteBehind, Buffer: buffer}, service.SubtaskPolicy{})
+
+	for _, id := range []string{"1", "2"} {
+		_, err := todoService.Create(context.Background(), dto.Todo{Id: id, Status: dto.TodoStatusPending})
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, positions, 2)
+	assert.Less(t, "i", positions[0])
+	assert.Less(t, positions[0], positions[1])
+	buffer.AssertExpectations(t)
+}
+
+func TestWriteBehindFlusher(t *testing.T) {
+	event := dto.OutboxEvent{Id: "e1", EventType: dto.EventTodoStatusChanged, AggregateId: "1"}
+	writes := []dto.BufferedWrite{
+		{Id: "w1", Todo: dto.Todo{Id: "1", Version: 3}, History: dto.TodoHistory{TodoId: "1"}, Event: &event},
+		{Id: "w2", Todo: dto.Todo{Id: "2", Version: 2}, History: dto.TodoHistory{TodoId: "2"}},
+	}
+
+	todoRepo := repository.NewTodoRepositoryMock()
+	todoHistory := repository.NewTodoHistoryRepositoryMock()
+	todoOutbox := repository.NewOutboxRepositoryMock()
+	todoCache := cache.NewRedisCacheMock()
+	buffer := cache.NewWriteBufferMock()
+
+	buffer.On("Claim", mock.Anything, 10).Return(writes, nil)
+	// w1 is new; w2 was already flushed before its ack was lost, so only its ack is repeated.
+	todoRepo.On("WriteNext", mock.Anything, writes[0].Todo).Return(int64(2), nil)
+	todoRepo.On("WriteNext", mock.Anything, writes[1].Todo).Return(int64(2), nil)
+	todoHistory.On("FindByTodoId", mock.Anything, "2").Return([]dto.TodoHistory{{TodoId: "2", After: &writes[1].Todo}}, nil)
+	todoHistory.On("Append", mock.Anything, writes[0].History).Return(nil).Once()
+	todoOutbox.On("Append", mock.Anything, event).Return(nil).Once()
+	buffer.On("Ack", mock.Anything, []string{"w1"}).Return(nil)
+	buffer.On("Ack", mock.Anything, []string{"w2"}).Return(nil)
+	todoRepo.On("FindByIdForUpdate", mock.Anything, "1").Return(writes[0].Todo, nil)
+	todoRepo.On("FindByIdForUpdate", mock.Anything, "2").Return(writes[1].Todo, nil)
+	todoCache.On("SetIfNewer", mock.Anything, cachedItem("1"), mock.Anything).Return(nil)
+	todoCache.On("SetIfNewer", mock.Anything, cachedItem("2"), mock.Anything).Return(nil)
+	todoCache.On("Set", mock.Anything, "todos:json:v1:gen", mock.Anything, mock.Anything).Return(nil)
+
+	flusher := service.NewWriteBehindFlusher(todoRepo, todoHistory, todoOutbox, repository.NewTransactorMock(), buffer, todoCache, serialization.NewJSONCodec(), "1", service.CacheTTL{})
+
+	flushed, err := flusher.Flush(context.Background(), 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, flushed)
+	todoRepo.AssertExpectations(t)
+	todoHistory.AssertExpectations(t)
+	todoOutbox.AssertExpectations(t)
+	todoCache.AssertExpectations(t)
+	buffer.AssertExpectations(t)
+}
+
+func TestWriteBehindFlusherKeepsVersionOrder(t *testing.T) {
+	now := time.Now()
+	created := dto.Todo{Id: "1", Topic: "Todo", Status: dto.TodoStatusPending, Version: 1}
+	created.Stamp(now)
+	done := created
+	done.Status, done.Version, done.UpdatedAt = dto.TodoStatusDone, 2, now.Add(time.Second)
+	// rival was projected from version 1 by another instance and flushed first.
+	rival := done
+	rival.Status, rival.UpdatedAt = dto.TodoStatusInProgress, now.Add(2*time.Second)
+	write := func(id string, todo dto.Todo, before *dto.Todo) dto.BufferedWrite {
+		event := dto.OutboxEvent{Id: "e" + id, EventType: dto.EventTodoStatusChanged, AggregateId: todo.Id}
+		return dto.BufferedWrite{Id: id, Todo: todo, History: dto.TodoHistory{TodoId: todo.Id, Before: before, After: &todo}, Event: &event}
+	}
+	first, second, lost := write("w1", created, nil), write("w2", done, &created), write("w3", rival, &created)
+
+	testCases := []struct {
+		description     string
+		flushed         []dto.BufferedWrite
+		claims          [][]dto.BufferedWrite
+		expectedFlushed []int
+		expectedAcks    []string
+		expected        dto.Todo
+		expectedHistory int
+	}{
+		{
+			description:     "A later version claimed first waits for the earlier one.",
+			claims:          [][]dto.BufferedWrite{{second}, {first}, {second}},
+			expectedFlushed: []int{0, 1, 1},
+			expectedAcks:    []string{"w1", "w2"},
+			expected:        done,
+			expectedHistory: 2,
+		},
+		{
+			description:     "Versions claimed together out of order are stored in order.",
+			claims:          [][]dto.BufferedWrite{{second, first}},
+			expectedFlushed: []int{2},
+			expectedAcks:    []string{"w1", "w2"},
+			expected:        done,
+			expectedHistory: 2,
+		},
+		{
+			description:     "A redelivered write is only acked.",
+			flushed:         []dto.BufferedWrite{first, second},
+			claims:          [][]dto.BufferedWrite{{second}},
+			expectedFlushed: []int{1},
+			expectedAcks:    []string{"w2"},
+			expected:        done,
+			expectedHistory: 2,
+		},
+		{
+			description:     "A write that lost a version to another write is dropped.",
+			flushed:         []dto.BufferedWrite{first, lost},
+			claims:          [][]dto.BufferedWrite{{second}},
+			expectedFlushed: []int{1},
+			expectedAcks:    []string{"w2"},
+			expected:        rival,
+			expectedHistory: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			todoRepo := memory.NewMemoryTodoRepository()
+			todoHistory := memory.NewMemoryTodoHistoryRepository()
+			todoOutbox := memory.NewMemoryOutboxRepository()
+			for _, write := range testCase.flushed {
+				_, err := todoRepo.WriteNext(ctx, write.Todo)
+				assert.NoError(t, err)
+				assert.NoError(t, todoHistory.Append(ctx, write.History))
+			}
+			buffer := cache.NewWriteBufferMock()
+			for _, claim := range testCase.claims {
+				buffer.On("Claim", mock.Anything, 10).Return(claim, nil).Once()
+			}
+			var acks []string
+			buffer.On("Ack", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				acks = append(acks, args.Get(1).([]string)...)
+			})
+			flusher := service.NewWriteBehindFlusher(todoRepo, todoHistory, todoOutbox, memory.NewMemoryTransactor(), buffer, memory.NewMemoryCache(), serialization.NewJSONCodec(), "1", service.CacheTTL{})
+
+			// Act
+			var flushed []int
+			for range testCase.claims {
+				count, err := flusher.Flush(ctx, 10)
+				assert.NoError(t, err)
+				flushed = append(flushed, count)
+			}
+
+			// Assert
+			assert.Equal(t, testCase.expectedFlushed, flushed)
+			assert.Equal(t, testCase.expectedAcks, acks)
+			stored, err := todoRepo.FindByIdForUpdate(ctx, "1")
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected.Status, stored.Status)
+			assert.Equal(t, testCase.expected.Version, stored.Version)
+			history, err := todoHistory.FindByTodoId(ctx, "1")
+			assert.NoError(t, err)
+			assert.Len(t, history, testCase.expectedHistory)
+			buffer.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCacheBypassed is returned without calling the cache while the circuit breaker is open.
+var ErrCacheBypassed = errors.New("cache bypassed")
+
+// Health reports whether the cache is currently bypassed.
+type Health interface {
+	Bypassed() bool
+}
+
+// CircuitBreaker stops calling the wrapped cache for a cooldown once threshold calls in a row have
+// failed. After the cooldown a single call is let through: success closes the breaker, failure
+// starts another cooldown. Misses are not failures, and calls ended by their context are ignored.
+type CircuitBreaker struct {
+	cache     Cache
+	threshold int
+	cooldown  time.Duration
+	onChange  func(bypassed bool)
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker wraps cache; onChange, when set, is called whenever the breaker opens or closes.
+func NewCircuitBreaker(cache Cache, threshold int, cooldown time.Duration, onChange func(bypassed bool)) *CircuitBreaker {
+	return &CircuitBreaker{cache: cache, threshold: max(threshold, 1), cooldown: cooldown, onChange: onChange}
+}
+
+func (b *CircuitBreaker) Bypassed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openedAt.IsZero()
+}
+
+func (b *CircuitBreaker) Get(ctx context.Context, key string) (string, error) {
+	if !b.allow() {
+		return "", ErrCacheBypassed
+	}
+	value, err := b.cache.Get(ctx, key)
+	return value, b.record(err)
+}
+
+func (b *CircuitBreaker) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if !b.allow() {
+		return ErrCacheBypassed
+	}
+	return b.record(b.cache.Set(ctx, key, value, ttl))
+}
+
+func (b *CircuitBreaker) Del(ctx context.Context, keys ...string) error {
+	if !b.allow() {
+		return ErrCacheBypassed
+	}
+	return b.record(b.cache.Del(ctx, keys...))
+}
+
+func (b *CircuitBreaker) Exists(ctx context.Context, key string) (bool, error) {
+	if !b.allow() {
+		return false, ErrCacheBypassed
+	}
+	exists, err := b.cache.Exists(ctx, key)
+	return exists, b.record(err)
+}
+
+func (b *CircuitBreaker) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	if !b.allow() {
+		return nil, ErrCacheBypassed
+	}
+	values, err := b.cache.MGet(ctx, keys)
+	return values, b.record(err)
+}
+
+func (b *CircuitBreaker) MSet(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if !b.allow() {
+		return ErrCacheBypassed
+	}
+	return b.record(b.cache.MSet(ctx, values, ttl))
+}
+
+func (b *CircuitBreaker) Scan(ctx context.Context, prefix string) ([]string, error) {
+	if !b.allow() {
+		return nil, ErrCacheBypassed
+	}
+	keys, err := b.cache.Scan(ctx, prefix)
+	return keys, b.record(err)
+}
+
+func (b *CircuitBreaker) SetIfNewer(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if !b.allow() {
+		return ErrCacheBypassed
+	}
+	return b.record(b.cache.SetIfNewer(ctx, values, ttl))
+}
+
+// allow reports whether a call may reach the cache, admitting one probe once the cooldown is over.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record counts the outcome of a call. A cancelled or expired context says nothing about the cache,
+// so it leaves the breaker as it was and only frees the probe slot for the next call.
+func (b *CircuitBreaker) record(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		b.mu.Lock()
+		b.probing = false
+		b.mu.Unlock()
+		return err
+	}
+	failed := err != nil && !errors.Is(err, ErrCacheMiss)
+
+	b.mu.Lock()
+	wasOpen := !b.openedAt.IsZero()
+	b.probing = false
+	if failed {
+		b.failures++
+		if wasOpen || b.failures >= b.threshold {
+			b.openedAt = time.Now()
+		}
+	} else {
+		b.failures = 0
+		b.openedAt = time.Time{}
+	}
+	isOpen := !b.openedAt.IsZero()
+	b.mu.Unlock()
+
+	if wasOpen != isOpen && b.onChange != nil {
+		b.onChange(isOpen)
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	// Arrange
+	inner := cache.NewRedisCacheMock()
+	inner.On("Get", mock.Anything, "down").Return("", errors.New("connection refused"))
+	inner.On("Get", mock.Anything, "missing").Return("", cache.ErrCacheMiss)
+	inner.On("Get", mock.Anything, "up").Return("1", nil)
+
+	var changes []bool
+	breaker := cache.NewCircuitBreaker(inner, 2, 20*time.Millisecond, func(bypassed bool) {
+		changes = append(changes, bypassed)
+	})
+	ctx := context.Background()
+
+	// Act & Assert
+	_, err := breaker.Get(ctx, "missing")
+	assert.Equal(t, cache.ErrCacheMiss, err)
+	_, _ = breaker.Get(ctx, "down")
+	assert.False(t, breaker.Bypassed())
+	_, _ = breaker.Get(ctx, "down")
+	assert.True(t, breaker.Bypassed())
+
+	_, err = breaker.Get(ctx, "up")
+	assert.Equal(t, cache.ErrCacheBypassed, err)
+
+	time.Sleep(30 * time.Millisecond)
+	value, err := breaker.Get(ctx, "up")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+	assert.False(t, breaker.Bypassed())
+
+	assert.Equal(t, []bool{true, false}, changes)
+	inner.AssertNumberOfCalls(t, "Get", 4)
+}
+
+func TestCircuitBreakerIgnoresContextErrors(t *testing.T) {
+	// Arrange
+	inner := cache.NewRedisCacheMock()
+	inner.On("Get", mock.Anything, "down").Return("", errors.New("connection refused"))
+	inner.On("Get", mock.Anything, "cancelled").Return("", context.Canceled)
+	inner.On("Get", mock.Anything, "expired").Return("", context.DeadlineExceeded)
+	inner.On("Get", mock.Anything, "up").Return("1", nil)
+
+	var changes []bool
+	breaker := cache.NewCircuitBreaker(inner, 2, 20*time.Millisecond, func(bypassed bool) {
+		changes = append(changes, bypassed)
+	})
+	ctx := context.Background()
+
+	// Act & Assert
+	// A cancelled call between two failures neither counts nor resets the count.
+	_, _ = breaker.Get(ctx, "down")
+	_, err := breaker.Get(ctx, "cancelled")
+	assert.Equal(t, context.Canceled, err)
+	_, _ = breaker.Get(ctx, "down")
+	assert.True(t, breaker.Bypassed())
+
+	// A half-open probe whose context ends keeps the breaker open and lets the next call probe.
+	time.Sleep(30 * time.Millisecond)
+	_, err = breaker.Get(ctx, "expired")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, breaker.Bypassed())
+	value, err := breaker.Get(ctx, "up")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+	assert.False(t, breaker.Bypassed())
+
+	assert.Equal(t, []bool{true, false}, changes)
+	inner.AssertNumberOfCalls(t, "Get", 5)
+}
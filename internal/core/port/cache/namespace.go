@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+type namespacedCache struct {
+	cache  Cache
+	prefix string
+}
+
+// NewNamespacedCache prefixes every key with "<namespace>:" so several applications or
+// environments can share one cache server. An empty namespace returns cache unchanged.
+func NewNamespacedCache(cache Cache, namespace string) Cache {
+	if namespace == "" {
+		return cache
+	}
+	return &namespacedCache{cache: cache, prefix: namespace + ":"}
+}
+
+func (n *namespacedCache) Get(ctx context.Context, key string) (string, error) {
+	return n.cache.Get(ctx, n.prefix+key)
+}
+
+func (n *namespacedCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return n.cache.Set(ctx, n.prefix+key, value, ttl)
+}
+
+func (n *namespacedCache) Del(ctx context.Context, keys ...string) error {
+	return n.cache.Del(ctx, n.keys(keys)...)
+}
+
+func (n *namespacedCache) Exists(ctx context.Context, key string) (bool, error) {
+	return n.cache.Exists(ctx, n.prefix+key)
+}
+
+func (n *namespacedCache) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	found, err := n.cache.MGet(ctx, n.keys(keys))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(found))
+	for key, value := range found {
+		values[key[len(n.prefix):]] = value
+	}
+	return values, nil
+}
+
+func (n *namespacedCache) MSet(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	return n.cache.MSet(ctx, n.values(values), ttl)
+}
+
+func (n *namespacedCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	found, err := n.cache.Scan(ctx, n.prefix+prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(found))
+	for _, key := range found {
+		keys = append(keys, key[len(n.prefix):])
+	}
+	return keys, nil
+}
+
+func (n *namespacedCache) SetIfNewer(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	return n.cache.SetIfNewer(ctx, n.values(values), ttl)
+}
+
+func (n *namespacedCache) keys(keys []string) []string {
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, n.prefix+key)
+	}
+	return prefixed
+}
+
+func (n *namespacedCache) values(values map[string]string) map[string]string {
+	prefixed := make(map[string]string, len(values))
+	for key, value := range values {
+		prefixed[n.prefix+key] = value
+	}
+	return prefixed
+}
@@ -0,0 +1,34 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNamespacedCache(t *testing.T) {
+	// Arrange
+	inner := cache.NewRedisCacheMock()
+	inner.On("Set", mock.Anything, "app:todos:gen", "1", time.Minute).Return(nil)
+	inner.On("MGet", mock.Anything, []string{"app:a", "app:b"}).Return(map[string]string{"app:a": "1"}, nil)
+	inner.On("Del", mock.Anything, []string{"app:a", "app:b"}).Return(nil)
+
+	namespaced := cache.NewNamespacedCache(inner, "app")
+
+	// Act
+	setErr := namespaced.Set(context.Background(), "todos:gen", "1", time.Minute)
+	values, mgetErr := namespaced.MGet(context.Background(), []string{"a", "b"})
+	delErr := namespaced.Del(context.Background(), "a", "b")
+
+	// Assert
+	assert.NoError(t, setErr)
+	assert.NoError(t, mgetErr)
+	assert.NoError(t, delErr)
+	assert.Equal(t, map[string]string{"a": "1"}, values)
+	assert.Same(t, inner, cache.NewNamespacedCache(inner, ""))
+	inner.AssertExpectations(t)
+}
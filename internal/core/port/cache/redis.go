@@ -1,8 +1,56 @@
 package cache
 
-import "context"
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
 
+var (
+	ErrCacheMiss   = errors.New("cache miss")
+	ErrUnversioned = errors.New("cache value has no version prefix")
+)
+
+// Cache stores string values by key. A ttl of zero keeps the entry until it is deleted.
 type Cache interface {
 	Get(ctx context.Context, key string) (string, error)
-	Set(ctx context.Context, key string, value string, expiration int64) error
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	// MGet returns the values found for keys; missing keys are left out of the map.
+	MGet(ctx context.Context, keys []string) (map[string]string, error)
+	MSet(ctx context.Context, values map[string]string, ttl time.Duration) error
+	// Scan returns every key starting with prefix. It walks the whole keyspace, so it is meant for
+	// maintenance jobs rather than request paths.
+	Scan(ctx context.Context, prefix string) ([]string, error)
+	// SetIfNewer stores each value unless the cached one has a higher version. The compare and write
+	// is atomic per key only: another client can see some keys written and others not yet. Values
+	// are built with Versioned, so concurrent writers cannot roll an entry back whatever codec
+	// encoded the payload.
+	SetIfNewer(ctx context.Context, values map[string]string, ttl time.Duration) error
+}
+
+// Versioned prefixes payload with its decimal version, the form SetIfNewer compares.
+func Versioned(version int64, payload []byte) string {
+	return strconv.FormatInt(version, 10) + ":" + string(payload)
+}
+
+// ValueVersion reads the version prefix of a value built with Versioned.
+func ValueVersion(value string) (int64, error) {
+	prefix, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, ErrUnversioned
+	}
+	return strconv.ParseInt(prefix, 10, 64)
+}
+
+// ValuePayload strips the version prefix of a value built with Versioned.
+func ValuePayload(value string) ([]byte, error) {
+	_, payload, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, ErrUnversioned
+	}
+	return []byte(payload), nil
 }
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -19,7 +20,37 @@ func (m *cacheMock) Get(ctx context.Context, key string) (string, error) {
 	return agrs.String(0), agrs.Error(1)
 }
 
-func (m *cacheMock) Set(ctx context.Context, key string, value string, expiration int64) error {
-	agrs := m.Called(ctx, key, value, expiration)
+func (m *cacheMock) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	agrs := m.Called(ctx, key, value, ttl)
+	return agrs.Error(0)
+}
+
+func (m *cacheMock) Del(ctx context.Context, keys ...string) error {
+	agrs := m.Called(ctx, keys)
+	return agrs.Error(0)
+}
+
+func (m *cacheMock) Exists(ctx context.Context, key string) (bool, error) {
+	agrs := m.Called(ctx, key)
+	return agrs.Bool(0), agrs.Error(1)
+}
+
+func (m *cacheMock) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	agrs := m.Called(ctx, keys)
+	return agrs.Get(0).(map[string]string), agrs.Error(1)
+}
+
+func (m *cacheMock) MSet(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	agrs := m.Called(ctx, values, ttl)
+	return agrs.Error(0)
+}
+
+func (m *cacheMock) Scan(ctx context.Context, prefix string) ([]string, error) {
+	agrs := m.Called(ctx, prefix)
+	return agrs.Get(0).([]string), agrs.Error(1)
+}
+
+func (m *cacheMock) SetIfNewer(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	agrs := m.Called(ctx, values, ttl)
 	return agrs.Error(0)
 }
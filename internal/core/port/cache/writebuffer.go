@@ -0,0 +1,19 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+)
+
+// WriteBuffer durably queues todo writes for the write-behind cache until they reach the
+// repository. Delivery is at least once: a write claimed but not acked is handed out again once
+// its claim times out, so flushing must be idempotent.
+type WriteBuffer interface {
+	Append(ctx context.Context, write dto.BufferedWrite) error
+	// Claim returns up to limit writes in the order they were appended, starting with writes whose
+	// earlier claim timed out. A write may be handed out while an earlier write to the same todo is
+	// claimed elsewhere, so the flusher restores the order of each todo's writes itself.
+	Claim(ctx context.Context, limit int) ([]dto.BufferedWrite, error)
+	Ack(ctx context.Context, ids ...string) error
+}
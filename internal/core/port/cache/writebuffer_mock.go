@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type writeBufferMock struct {
+	mock.Mock
+}
+
+func NewWriteBufferMock() *writeBufferMock {
+	return &writeBufferMock{}
+}
+
+func (m *writeBufferMock) Append(ctx context.Context, write dto.BufferedWrite) error {
+	args := m.Called(ctx, write)
+	return args.Error(0)
+}
+
+func (m *writeBufferMock) Claim(ctx context.Context, limit int) ([]dto.BufferedWrite, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]dto.BufferedWrite), args.Error(1)
+}
+
+func (m *writeBufferMock) Ack(ctx context.Context, ids ...string) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
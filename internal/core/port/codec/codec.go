@@ -0,0 +1,14 @@
+package codec
+
+import "errors"
+
+var ErrUnsupportedType = errors.New("codec does not support this type")
+
+// Codec encodes the values stored in the cache.
+type Codec interface {
+	// Name identifies the encoding. It is part of every cache key, so a value written with one
+	// codec is never read with another.
+	Name() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
@@ -0,0 +1,11 @@
+package event
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, event dto.OutboxEvent) error
+}
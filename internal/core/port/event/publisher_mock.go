@@ -0,0 +1,21 @@
+package event
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type publisherMock struct {
+	mock.Mock
+}
+
+func NewPublisherMock() *publisherMock {
+	return &publisherMock{}
+}
+
+func (m *publisherMock) Publish(ctx context.Context, event dto.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
@@ -0,0 +1,12 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+)
+
+// Notifier tells someone that a todo reminder came due.
+type Notifier interface {
+	Notify(ctx context.Context, reminder dto.TodoReminderDue) error
+}
@@ -0,0 +1,21 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type notifierMock struct {
+	mock.Mock
+}
+
+func NewNotifierMock() *notifierMock {
+	return &notifierMock{}
+}
+
+func (m *notifierMock) Notify(ctx context.Context, reminder dto.TodoReminderDue) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+)
+
+type TodoHistoryRepository interface {
+	Append(ctx context.Context, entry dto.TodoHistory) error
+	FindByTodoId(ctx context.Context, todoId string) ([]dto.TodoHistory, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type todoHistoryRepositoryMock struct {
+	mock.Mock
+}
+
+func NewTodoHistoryRepositoryMock() *todoHistoryRepositoryMock {
+	return &todoHistoryRepositoryMock{}
+}
+
+func (m *todoHistoryRepositoryMock) Append(ctx context.Context, entry dto.TodoHistory) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *todoHistoryRepositoryMock) FindByTodoId(ctx context.Context, todoId string) ([]dto.TodoHistory, error) {
+	args := m.Called(ctx, todoId)
+	return args.Get(0).([]dto.TodoHistory), args.Error(1)
+}
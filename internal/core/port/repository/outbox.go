@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+)
+
+type OutboxRepository interface {
+	Append(ctx context.Context, event dto.OutboxEvent) error
+	// FindUnpublished returns the oldest unpublished events that failed fewer than maxAttempts times
+	// (any number when maxAttempts is not positive). Events past the cut-off are dead letters, left in
+	// place for inspection. Inside a transaction the rows stay claimed by the caller until it ends.
+	FindUnpublished(ctx context.Context, limit int, maxAttempts int) ([]dto.OutboxEvent, error)
+	MarkPublished(ctx context.Context, ids []string, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, id string) error
+}
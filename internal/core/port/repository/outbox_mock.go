@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type outboxRepositoryMock struct {
+	mock.Mock
+}
+
+func NewOutboxRepositoryMock() *outboxRepositoryMock {
+	return &outboxRepositoryMock{}
+}
+
+func (m *outboxRepositoryMock) Append(ctx context.Context, event dto.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *outboxRepositoryMock) FindUnpublished(ctx context.Context, limit int, maxAttempts int) ([]dto.OutboxEvent, error) {
+	args := m.Called(ctx, limit, maxAttempts)
+	return args.Get(0).([]dto.OutboxEvent), args.Error(1)
+}
+
+func (m *outboxRepositoryMock) MarkPublished(ctx context.Context, ids []string, publishedAt time.Time) error {
+	args := m.Called(ctx, ids, publishedAt)
+	return args.Error(0)
+}
+
+func (m *outboxRepositoryMock) MarkFailed(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
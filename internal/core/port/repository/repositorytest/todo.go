@@ -0,0 +1,511 @@
+// Package repositorytest holds the behaviour every repository implementation must share, run by
+// each adapter's tests against its own storage.
+package repositorytest
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// TodoRepositories returns a todo repository over empty storage and the transactor for it.
+type TodoRepositories func(t *testing.T) (repository.TodoRepository, repository.Transactor)
+
+// TestTodoRepository runs the todo repository contract against fresh repositories from newRepositories.
+func TestTodoRepository(t *testing.T, newRepositories TodoRepositories) {
+	testCases := []struct {
+		description string
+		run         func(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor)
+	}{
+		{description: "Save stores a todo once and FindById reads it back.", run: testSave},
+		{description: "FindAll pages through every sort and filter.", run: testFindAll},
+		{description: "FindAll filters and sorts by time across zones.", run: testFindAllAcrossZones},
+		{description: "Update bumps the version and checks the expected one.", run: testUpdate},
+		{description: "Delete moves a todo to the trash and Restore brings it back.", run: testTrash},
+		{description: "Search ranks live todos and escapes the snippets.", run: testSearch},
+		{description: "Search sees writes of the transaction it runs in.", run: testSearchInTransaction},
+		{description: "Subtasks are listed by level and counted for progress.", run: testSubtasks},
+		{description: "Purge refuses a todo while subtasks remain below it.", run: testPurge},
+		{description: "PurgeDeletedBefore keeps the todos above a subtask that stays.", run: testPurgeDeletedBefore},
+		{description: "Positions are looked up within a priority.", run: testPositions},
+		{description: "Due todos and reminders are found by time.", run: testDue},
+		{description: "Due todos and reminders are found by time across zones.", run: testDueAcrossZones},
+		{description: "WriteNext only writes the next version.", run: testWriteNext},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			repo, transactor := newRepositories(t)
+			testCase.run(t, context.Background(), repo, transactor)
+		})
+	}
+}
+
+func testSave(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	save(t, ctx, repo, todo("1", nil))
+
+	found, err := repo.FindById(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Todo 1", found.Topic)
+	assert.Equal(t, dto.TodoStatusPending, found.Status)
+	assert.Equal(t, int64(1), found.Version)
+
+	assert.Error(t, repo.Save(ctx, todo("1", nil)))
+	_, err = repo.FindById(ctx, "2")
+	assert.Equal(t, dto.ErrTodoNotFound, err)
+}
+
+func testFindAll(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	now := time.Now().Truncate(time.Second)
+	east, west := time.FixedZone("UTC+7", 7*60*60), time.FixedZone("UTC-5", -5*60*60)
+	// Pairs of todos share a status, priority, position, topic and timestamps, so every sort has ties.
+	listed := func(id string, status dto.TodoStatus, priority dto.TodoPriority, position string, created time.Time) dto.Todo {
+		todo := todo(id, nil)
+		todo.Topic, todo.Description = "Todo "+position, "About "+string(status)
+		todo.Status, todo.Priority, todo.Position = status, priority, position
+		todo.CreatedAt, todo.UpdatedAt = created, created.Add(time.Minute)
+		if status == dto.TodoStatusDone {
+			completed := created.Add(time.Hour)
+			todo.CompletedAt = &completed
+		}
+		return todo
+	}
+	todos := []dto.Todo{
+		listed("a", dto.TodoStatusPending, dto.TodoPriorityHigh, "i", now.Add(-5*time.Hour).In(east)),
+		listed("b", dto.TodoStatusPending, dto.TodoPriorityHigh, "i", now.Add(-5*time.Hour).In(west)),
+		listed("c", dto.TodoStatusDone, dto.TodoPriorityNone, "m", now.Add(-4*time.Hour).UTC()),
+		listed("d", dto.TodoStatusDone, dto.TodoPriorityNone, "m", now.Add(-4*time.Hour).In(east)),
+		listed("e", dto.TodoStatusInProgress, dto.TodoPriorityUrgent, "d", now.Add(-3*time.Hour).In(west)),
+		listed("f", dto.TodoStatusReopened, dto.TodoPriorityUrgent, "r", now.Add(-2*time.Hour).In(east)),
+		listed("g", dto.TodoStatusDone, dto.TodoPriorityHigh, "d", now.Add(-time.Hour).In(west)),
+	}
+	for _, todo := range todos {
+		save(t, ctx, repo, todo)
+	}
+	trashed := listed("trashed", dto.TodoStatusPending, dto.TodoPriorityHigh, "i", now.Add(-5*time.Hour))
+	save(t, ctx, repo, trashed)
+	_, err := repo.Delete(ctx, dto.TodoInputDelete{Id: "trashed"})
+	assert.NoError(t, err)
+
+	type listCase struct {
+		description string
+		query       dto.TodoQuery
+	}
+	testCases := []listCase{
+		{description: "status filter", query: dto.TodoQuery{Status: dto.TodoStatusDone}},
+		{description: "created range", query: dto.TodoQuery{Created: dto.TimeRange{After: now.Add(-4 * time.Hour).In(west), Before: now.Add(-time.Hour).In(east)}}},
+		{description: "updated range", query: dto.TodoQuery{Updated: dto.TimeRange{Before: now.Add(-4 * time.Hour).In(east)}}},
+		{description: "completed range", query: dto.TodoQuery{Completed: dto.TimeRange{After: now.Add(-3 * time.Hour).In(east), Before: now.In(west)}}},
+		{description: "status filter and created range", query: dto.TodoQuery{Status: dto.TodoStatusPending, Created: dto.TimeRange{After: now.Add(-6 * time.Hour).In(east)}}},
+	}
+	for _, name := range slices.Sorted(maps.Keys(dto.TodoSortFields)) {
+		testCases = append(testCases, listCase{description: name, query: dto.TodoQuery{Sort: name}}, listCase{description: "-" + name, query: dto.TodoQuery{Sort: "-" + name}})
+	}
+
+	for _, testCase := range testCases {
+		query, err := testCase.query.Normalize()
+		assert.NoError(t, err)
+		expected := []dto.Todo{}
+		for _, todo := range todos {
+			if query.Matches(todo) {
+				expected = append(expected, todo)
+			}
+		}
+		slices.SortFunc(expected, query.Compare)
+
+		// Pages of two walk across the ties, each starting from the cursor the last one returned.
+		query.Limit, query.IncludeTotal = 2, true
+		walked := []dto.Todo{}
+		for pages := 0; pages <= len(todos); pages++ {
+			page, err := repo.FindAll(ctx, query)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, len(page.Todos), query.Limit, testCase.description)
+			if assert.NotNil(t, page.Total, testCase.description) {
+				assert.Equal(t, int64(len(expected)), *page.Total, testCase.description)
+			}
+			walked = append(walked, page.Todos...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		assert.Equal(t, ids(expected), ids(walked), testCase.description)
+	}
+}
+
+func testFindAllAcrossZones(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	now := time.Now().Truncate(time.Second)
+	east, west := time.FixedZone("UTC+7", 7*60*60), time.FixedZone("UTC-5", -5*60*60)
+	stamped := func(id string, created time.Time, updated time.Time, done bool) dto.Todo {
+		todo := todo(id, nil)
+		todo.CreatedAt, todo.UpdatedAt = created, updated
+		if done {
+			todo.Status, todo.CompletedAt = dto.TodoStatusDone, &updated
+		}
+		return todo
+	}
+	for _, todo := range []dto.Todo{
+		stamped("east", now.Add(-3*time.Hour).In(east), now.Add(-time.Hour).In(east), true),
+		stamped("west", now.Add(-2*time.Hour).In(west), now.Add(-2*time.Hour).In(west), false),
+		stamped("utc", now.Add(-time.Hour).UTC(), now.Add(-3*time.Hour).UTC(), true),
+	} {
+		save(t, ctx, repo, todo)
+	}
+
+	testCases := []struct {
+		description string
+		query       dto.TodoQuery
+		expected    []string
+	}{
+		{description: "created_at", query: dto.TodoQuery{Sort: "created_at"}, expected: []string{"east", "west", "utc"}},
+		{description: "-updated_at", query: dto.TodoQuery{Sort: "-updated_at"}, expected: []string{"east", "west", "utc"}},
+		{description: "completed_at", query: dto.TodoQuery{Sort: "completed_at"}, expected: []string{"utc", "east"}},
+		{
+			description: "created range",
+			query:       dto.TodoQuery{Sort: "created_at", Created: dto.TimeRange{After: now.Add(-150 * time.Minute).In(west), Before: now.In(east)}},
+			expected:    []string{"west", "utc"},
+		},
+		{
+			description: "updated range",
+			query:       dto.TodoQuery{Sort: "created_at", Updated: dto.TimeRange{Before: now.Add(-90 * time.Minute).In(east)}},
+			expected:    []string{"west", "utc"},
+		},
+		{
+			description: "completed range",
+			query:       dto.TodoQuery{Sort: "created_at", Completed: dto.TimeRange{After: now.Add(-2 * time.Hour).In(west)}},
+			expected:    []string{"east"},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase.query.Limit = 10
+		page, err := repo.FindAll(ctx, testCase.query)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.expected, ids(page.Todos), testCase.description)
+	}
+}
+
+func testUpdate(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	save(t, ctx, repo, todo("1", nil))
+
+	updated, err := repo.Update(ctx, dto.TodoInputUpdateStatus{Id: "1", Status: dto.TodoStatusDone, ExpectedVersion: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.TodoStatusDone, updated.Status)
+	assert.Equal(t, int64(2), updated.Version)
+
+	_, err = repo.Update(ctx, dto.TodoInputUpdateStatus{Id: "1", Status: dto.TodoStatusReopened, ExpectedVersion: 1})
+	assert.Equal(t, dto.ErrVersionConflict, err)
+	_, err = repo.Update(ctx, dto.TodoInputUpdateStatus{Id: "2", Status: dto.TodoStatusDone})
+	assert.Equal(t, dto.ErrTodoNotFound, err)
+}
+
+func testTrash(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	save(t, ctx, repo, todo("1", nil))
+
+	deleted, err := repo.Delete(ctx, dto.TodoInputDelete{Id: "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted.Version)
+	_, err = repo.FindById(ctx, "1")
+	assert.Equal(t, dto.ErrTodoNotFound, err)
+	trash, err := repo.FindTrash(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, ids(trash))
+
+	restored, err := repo.Restore(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), restored.Version)
+	_, err = repo.FindById(ctx, "1")
+	assert.NoError(t, err)
+	_, err = repo.Restore(ctx, "1")
+	assert.Equal(t, dto.ErrTodoNotFound, err)
+}
+
+func testSearch(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	rent := todo("1", nil)
+	rent.Topic, rent.Description = "Pay rent", "Before the <b>1st</b>"
+	car := todo("2", nil)
+	car.Topic, car.Description = "Book a car", "Rent one for the trip"
+	trashed := todo("3", nil)
+	trashed.Topic = "Rent receipts"
+	other := todo("4", nil)
+	other.Topic = "Water the plants"
+	for _, todo := range []dto.Todo{rent, car, trashed, other} {
+		save(t, ctx, repo, todo)
+	}
+	_, err := repo.Delete(ctx, dto.TodoInputDelete{Id: "3"})
+	assert.NoError(t, err)
+
+	results, err := repo.Search(ctx, dto.TodoSearchQuery{Text: "rent 1st", Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "1", results[0].Todo.Id)
+	assert.Equal(t, "Before the &lt;b&gt;<mark>1st</mark>&lt;/b&gt;", results[0].DescriptionSnippet)
+
+	results, err = repo.Search(ctx, dto.TodoSearchQuery{Text: "rent", Limit: 10})
+	assert.NoError(t, err)
+	var found []string
+	for _, result := range results {
+		found = append(found, result.Todo.Id)
+	}
+	assert.Equal(t, []string{"1", "2"}, found, "a match in the topic ranks above one in the description")
+
+	results, err = repo.Search(ctx, dto.TodoSearchQuery{Text: "rent", Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func testSearchInTransaction(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := repo.Save(ctx, todo("1", nil)); err != nil {
+			return err
+		}
+		results, err := repo.Search(ctx, dto.TodoSearchQuery{Text: "todo", Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func testSubtasks(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	parentId, childId := "p", "c2"
+	first, second, grandchild := todo("c1", &parentId), todo("c2", &parentId), todo("g", &childId)
+	first.Status, first.Position, second.Position = dto.TodoStatusDone, "a", "b"
+	for _, todo := range []dto.Todo{todo("p", nil), second, first, grandchild} {
+		save(t, ctx, repo, todo)
+	}
+
+	children, err := repo.FindChildren(ctx, "p")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c1", "c2"}, ids(children))
+
+	subtree, err := repo.FindSubtree(ctx, "p")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c1", "c2", "g"}, ids(subtree))
+
+	progress, err := repo.Progress(ctx, []string{"p", "c2", "g"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]dto.TodoProgress{"p": dto.NewTodoProgress(1, 3), "c2": dto.NewTodoProgress(0, 1)}, progress)
+
+	// A trashed subtask hides itself and everything below it.
+	_, err = repo.Delete(ctx, dto.TodoInputDelete{Id: "c2"})
+	assert.NoError(t, err)
+	subtree, err = repo.FindSubtree(ctx, "p")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c1"}, ids(subtree))
+}
+
+func testPurge(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	parentId := "p"
+	save(t, ctx, repo, todo("p", nil))
+	save(t, ctx, repo, todo("c", &parentId))
+	for _, id := range []string{"c", "p"} {
+		_, err := repo.Delete(ctx, dto.TodoInputDelete{Id: id})
+		assert.NoError(t, err)
+	}
+
+	err := repo.Purge(ctx, "p")
+	assert.Equal(t, &dto.OpenSubtasksError{TodoId: "p", Open: []string{"c"}}, err)
+
+	assert.NoError(t, repo.Purge(ctx, "c"))
+	assert.NoError(t, repo.Purge(ctx, "p"))
+	assert.Equal(t, dto.ErrTodoNotFound, repo.Purge(ctx, "p"))
+
+	save(t, ctx, repo, todo("live", nil))
+	assert.Equal(t, dto.ErrTodoNotFound, repo.Purge(ctx, "live"))
+}
+
+func testPurgeDeletedBefore(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	// a stays above its live subtask b; c and its trashed subtask d go together, as does e.
+	a, c := "a", "c"
+	for _, todo := range []dto.Todo{todo("a", nil), todo("b", &a), todo("c", nil), todo("d", &c), todo("e", nil)} {
+		save(t, ctx, repo, todo)
+	}
+	for _, id := range []string{"a", "d", "c", "e"} {
+		_, err := repo.Delete(ctx, dto.TodoInputDelete{Id: id})
+		assert.NoError(t, err)
+	}
+
+	purged, err := repo.PurgeDeletedBefore(ctx, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "d", "e"}, sorted(ids(purged)))
+
+	trash, err := repo.FindTrash(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, ids(trash))
+}
+
+func testPositions(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	last, err := repo.LastPosition(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "", last)
+
+	high := todo("h", nil)
+	high.Priority, high.Position = dto.TodoPriorityHigh, "i"
+	trashed := todo("x", nil)
+	trashed.Position = "z"
+	for _, todo := range []dto.Todo{high, positioned("n1", "c"), positioned("n2", "m"), positioned("n3", "t"), trashed} {
+		save(t, ctx, repo, todo)
+	}
+	_, err = repo.Delete(ctx, dto.TodoInputDelete{Id: "x"})
+	assert.NoError(t, err)
+
+	last, err = repo.LastPosition(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "t", last)
+
+	for _, lookup := range []struct {
+		priority dto.TodoPriority
+		position string
+		after    bool
+		expected string
+	}{
+		{dto.TodoPriorityNone, "m", true, "t"},
+		{dto.TodoPriorityNone, "m", false, "c"},
+		{dto.TodoPriorityNone, "t", true, ""},
+		{dto.TodoPriorityHigh, "m", false, "i"},
+		{dto.TodoPriorityHigh, "i", true, ""},
+	} {
+		adjacent, err := repo.AdjacentPosition(ctx, lookup.priority, lookup.position, lookup.after)
+		assert.NoError(t, err)
+		assert.Equal(t, lookup.expected, adjacent, "%v %q after=%v", lookup.priority, lookup.position, lookup.after)
+	}
+}
+
+func testDue(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	now := time.Now().Truncate(time.Second)
+	due := func(id string, offset time.Duration, reminders ...time.Duration) dto.Todo {
+		at := now.Add(offset)
+		todo := todo(id, nil)
+		todo.Due, todo.Reminders = dto.DueDate{At: &at}, reminders
+		return todo
+	}
+	done := due("done", -time.Hour)
+	done.Status = dto.TodoStatusDone
+	for _, todo := range []dto.Todo{due("late", -time.Hour), due("soon", time.Hour, 2*time.Hour), due("later", 3*time.Hour, time.Hour), done, todo("undated", nil)} {
+		save(t, ctx, repo, todo)
+	}
+
+	overdue, err := repo.FindDue(ctx, dto.TimeRange{Before: now}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"late"}, ids(overdue))
+	upcoming, err := repo.FindDue(ctx, dto.TimeRange{After: now, Before: now.Add(4 * time.Hour)}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"soon"}, ids(upcoming))
+
+	// soon's only reminder was already past when it was saved, so only later's is left.
+	reminders, err := repo.FindRemindersDue(ctx, now.Add(3*time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"later"}, ids(reminders))
+
+	assert.NoError(t, repo.SetNextReminder(ctx, "later", nil))
+	reminders, err = repo.FindRemindersDue(ctx, now.Add(3*time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, reminders)
+}
+
+func testDueAcrossZones(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	now := time.Now().Truncate(time.Second)
+	east, west := time.FixedZone("UTC+7", 7*60*60), time.FixedZone("UTC-5", -5*60*60)
+	due := func(id string, at time.Time, reminders ...time.Duration) dto.Todo {
+		todo := todo(id, nil)
+		todo.Due, todo.Reminders = dto.DueDate{At: &at}, reminders
+		return todo
+	}
+	for _, todo := range []dto.Todo{
+		due("west", now.Add(-10*time.Minute).In(west)),
+		due("east", now.Add(-30*time.Minute).In(east)),
+		due("utc", now.Add(-20*time.Minute).UTC()),
+		due("tomorrow", now.Add(24*time.Hour).In(east)),
+		due("reminded", now.Add(2*time.Hour).In(east), 90*time.Minute),
+		due("unreminded", now.Add(5*time.Hour).In(west), time.Hour),
+	} {
+		save(t, ctx, repo, todo)
+	}
+
+	overdue, err := repo.FindDue(ctx, dto.TimeRange{Before: now.In(west)}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"east", "utc", "west"}, ids(overdue))
+	upcoming, err := repo.FindDue(ctx, dto.TimeRange{After: now.Add(-15 * time.Minute).In(east), Before: now.Add(3 * time.Hour).In(west)}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"west", "reminded"}, ids(upcoming))
+
+	reminders, err := repo.FindRemindersDue(ctx, now.Add(time.Hour).In(west), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"reminded"}, ids(reminders))
+}
+
+func testWriteNext(t *testing.T, ctx context.Context, repo repository.TodoRepository, transactor repository.Transactor) {
+	first := todo("1", nil)
+	second := first
+	second.Version, second.Status = 2, dto.TodoStatusDone
+	third := second
+	third.Version, third.Status = 3, dto.TodoStatusPending
+	late := todo("2", nil)
+	late.Version = 2
+
+	for _, write := range []struct {
+		todo     dto.Todo
+		expected int64
+	}{
+		{todo: late, expected: 0},
+		{todo: third, expected: 0},
+		{todo: first, expected: 0},
+		{todo: first, expected: 1},
+		{todo: third, expected: 1},
+		{todo: second, expected: 1},
+		{todo: first, expected: 2},
+	} {
+		var stored int64
+		err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			stored, err = repo.WriteNext(ctx, write.todo)
+			return err
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, write.expected, stored, "%s at version %d", write.todo.Id, write.todo.Version)
+	}
+
+	found, err := repo.FindById(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), found.Version)
+	assert.Equal(t, dto.TodoStatusDone, found.Status)
+	_, err = repo.FindById(ctx, "2")
+	assert.Equal(t, dto.ErrTodoNotFound, err)
+}
+
+// todo returns a pending todo at version 1, as the service would save it.
+func todo(id string, parentId *string) dto.Todo {
+	todo := dto.Todo{Id: id, ParentId: parentId, Topic: "Todo " + id, Status: dto.TodoStatusPending, Position: "i", Version: 1}
+	todo.Stamp(time.Now())
+	return todo
+}
+
+func positioned(id string, position string) dto.Todo {
+	todo := todo(id, nil)
+	todo.Position = position
+	return todo
+}
+
+func save(t *testing.T, ctx context.Context, repo repository.TodoRepository, todo dto.Todo) {
+	t.Helper()
+	assert.NoError(t, repo.Save(ctx, todo))
+}
+
+func ids(todos []dto.Todo) []string {
+	ids := []string{}
+	for _, todo := range todos {
+		ids = append(ids, todo.Id)
+	}
+	return ids
+}
+
+func sorted(ids []string) []string {
+	slices.Sort(ids)
+	return ids
+}
@@ -1,10 +1,50 @@
 package repository
 
-import "github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
+)
 
 type TodoRepository interface {
-	FindAll() ([]dto.Todo, error)
-	Save(dto.Todo) error
-	Update(dto.TodoInputUpdateStatus) error
-	Delete(dto.TodoInputDelete) error
+	FindAll(ctx context.Context, query dto.TodoQuery) (dto.TodoPage, error)
+	FindById(ctx context.Context, id string) (dto.Todo, error)
+	FindByIdForUpdate(ctx context.Context, id string) (dto.Todo, error)
+	Search(ctx context.Context, query dto.TodoSearchQuery) ([]dto.TodoSearchResult, error)
+	// FindChildren returns the live subtasks directly below a todo in rank order.
+	FindChildren(ctx context.Context, parentId string) ([]dto.Todo, error)
+	// FindSubtree returns every live todo below a todo, level by level and in rank order within a
+	// level. A trashed subtask hides the subtasks below it.
+	FindSubtree(ctx context.Context, rootId string) ([]dto.Todo, error)
+	// Progress counts the live and the done todos below each of ids, leaving out todos with none.
+	Progress(ctx context.Context, ids []string) (map[string]dto.TodoProgress, error)
+	Save(ctx context.Context, input dto.Todo) error
+	Update(ctx context.Context, input dto.TodoInputUpdateStatus) (dto.Todo, error)
+	Schedule(ctx context.Context, input dto.TodoInputSchedule) (dto.Todo, error)
+	Move(ctx context.Context, input dto.TodoInputMove) (dto.Todo, error)
+	// LastPosition returns the greatest position of a live todo, or "" when there is none.
+	LastPosition(ctx context.Context) (string, error)
+	// AdjacentPosition returns the closest position of a live todo with priority after position,
+	// or before it when after is false, or "" when there is none.
+	AdjacentPosition(ctx context.Context, priority dto.TodoPriority, position string, after bool) (string, error)
+	Delete(ctx context.Context, input dto.TodoInputDelete) (dto.Todo, error)
+	FindTrash(ctx context.Context) ([]dto.Todo, error)
+	Restore(ctx context.Context, id string) (dto.Todo, error)
+	// Purge deletes a trashed todo, failing with an *OpenSubtasksError while subtasks remain below it.
+	Purge(ctx context.Context, id string) error
+	// PurgeDeletedBefore deletes the todos trashed before before, except those with a subtask below
+	// them that is not deleted too; they are purged by a later run once their subtasks are.
+	PurgeDeletedBefore(ctx context.Context, before time.Time) ([]dto.Todo, error)
+	// WriteNext stores todo, trashed or not, when it is the version right after the stored one and
+	// returns the version stored before, zero for a missing todo. A missing todo is only inserted at
+	// version 1, so a purged todo is not brought back by a late write. Call it inside a transaction.
+	WriteNext(ctx context.Context, todo dto.Todo) (int64, error)
+	// FindDue returns up to limit todos that are not done and fall due within r, soonest first.
+	FindDue(ctx context.Context, r dto.TimeRange, limit int) ([]dto.Todo, error)
+	// FindRemindersDue locks up to limit todos that are not done and whose next reminder is due by
+	// now, skipping rows another dispatcher holds.
+	FindRemindersDue(ctx context.Context, now time.Time, limit int) ([]dto.Todo, error)
+	// SetNextReminder records when a todo reminds next, nil for never, without changing its version.
+	SetNextReminder(ctx context.Context, id string, next *time.Time) error
 }
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/VanillaSkys/todo_fiber/internal/core/domain/dto"
 	"github.com/stretchr/testify/mock"
 )
@@ -13,22 +16,112 @@ func NewTodoRepositoryMock() *todoRepositoryMock {
 	return &todoRepositoryMock{}
 }
 
-func (m *todoRepositoryMock) FindAll() ([]dto.Todo, error) {
-	args := m.Called()
+func (m *todoRepositoryMock) FindAll(ctx context.Context, query dto.TodoQuery) (dto.TodoPage, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(dto.TodoPage), args.Error(1)
+}
+
+func (m *todoRepositoryMock) FindById(ctx context.Context, id string) (dto.Todo, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) FindByIdForUpdate(ctx context.Context, id string) (dto.Todo, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) Search(ctx context.Context, query dto.TodoSearchQuery) ([]dto.TodoSearchResult, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]dto.TodoSearchResult), args.Error(1)
+}
+
+func (m *todoRepositoryMock) FindChildren(ctx context.Context, parentId string) ([]dto.Todo, error) {
+	args := m.Called(ctx, parentId)
 	return args.Get(0).([]dto.Todo), args.Error(1)
 }
 
-func (m *todoRepositoryMock) Save(input dto.Todo) error {
-	args := m.Called(input)
+func (m *todoRepositoryMock) FindSubtree(ctx context.Context, rootId string) ([]dto.Todo, error) {
+	args := m.Called(ctx, rootId)
+	return args.Get(0).([]dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) Progress(ctx context.Context, ids []string) (map[string]dto.TodoProgress, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(map[string]dto.TodoProgress), args.Error(1)
+}
+
+func (m *todoRepositoryMock) Save(ctx context.Context, input dto.Todo) error {
+	args := m.Called(ctx, input)
 	return args.Error(0)
 }
 
-func (m *todoRepositoryMock) Update(input dto.TodoInputUpdateStatus) error {
-	args := m.Called(input)
+func (m *todoRepositoryMock) Update(ctx context.Context, input dto.TodoInputUpdateStatus) (dto.Todo, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) Schedule(ctx context.Context, input dto.TodoInputSchedule) (dto.Todo, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) Delete(ctx context.Context, input dto.TodoInputDelete) (dto.Todo, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) FindTrash(ctx context.Context) ([]dto.Todo, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) Restore(ctx context.Context, id string) (dto.Todo, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) Purge(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *todoRepositoryMock) Delete(input dto.TodoInputDelete) error {
-	args := m.Called(input)
+func (m *todoRepositoryMock) PurgeDeletedBefore(ctx context.Context, before time.Time) ([]dto.Todo, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).([]dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) WriteNext(ctx context.Context, todo dto.Todo) (int64, error) {
+	args := m.Called(ctx, todo)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *todoRepositoryMock) FindDue(ctx context.Context, r dto.TimeRange, limit int) ([]dto.Todo, error) {
+	args := m.Called(ctx, r, limit)
+	return args.Get(0).([]dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) FindRemindersDue(ctx context.Context, now time.Time, limit int) ([]dto.Todo, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) SetNextReminder(ctx context.Context, id string, next *time.Time) error {
+	args := m.Called(ctx, id, next)
 	return args.Error(0)
 }
+
+func (m *todoRepositoryMock) Move(ctx context.Context, input dto.TodoInputMove) (dto.Todo, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(dto.Todo), args.Error(1)
+}
+
+func (m *todoRepositoryMock) LastPosition(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *todoRepositoryMock) AdjacentPosition(ctx context.Context, priority dto.TodoPriority, position string, after bool) (string, error) {
+	args := m.Called(ctx, priority, position, after)
+	return args.String(0), args.Error(1)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import "context"
+
+type transactorMock struct{}
+
+// NewTransactorMock returns a Transactor that runs fn directly.
+func NewTransactorMock() *transactorMock {
+	return &transactorMock{}
+}
+
+func (m *transactorMock) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,20 @@
+package infrastructure
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+func InitConfig() error {
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath(".")
+	viper.AutomaticEnv()
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+	return nil
+}
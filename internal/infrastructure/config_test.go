@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitConfig(t *testing.T) {
+	testCases := []struct {
+		description string
+		env         map[string]string
+		key         string
+		expected    string
+	}{
+		{description: "a nested key is read from its underscored variable", env: map[string]string{"ADMIN_TOKEN": "secret"}, key: "admin.token", expected: "secret"},
+		{description: "a variable overrides the file", env: map[string]string{"APP_PORT": "9090"}, key: "app.port", expected: "9090"},
+		{description: "the file is used without a variable", key: "app.port", expected: "8080"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("app:\n  port: 8080\nadmin:\n  token:\n"), 0o600))
+			workingDir, err := os.Getwd()
+			assert.NoError(t, err)
+			assert.NoError(t, os.Chdir(dir))
+			t.Cleanup(func() { os.Chdir(workingDir) })
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			for name, value := range testCase.env {
+				t.Setenv(name, value)
+			}
+
+			// Act
+			err = InitConfig()
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, viper.GetString(testCase.key))
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	postgres, err := load("sql/postgres")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, postgres)
+
+	sqlite, err := load("sql/sqlite")
+	assert.NoError(t, err)
+
+	assert.Len(t, sqlite, len(postgres), "every dialect must ship the same versions")
+	for i, migration := range postgres {
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+		if i > 0 {
+			assert.Greater(t, migration.Version, postgres[i-1].Version)
+		}
+		if i < len(sqlite) {
+			assert.Equal(t, migration.Version, sqlite[i].Version)
+			assert.Equal(t, migration.Name, sqlite[i].Name)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+ALTER TABLE todos ADD COLUMN deleted_at timestamptz;
+CREATE INDEX idx_todos_deleted_at ON todos (deleted_at);
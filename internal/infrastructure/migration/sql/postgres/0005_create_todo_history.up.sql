@@ -0,0 +1,11 @@
+CREATE TABLE todo_history (
+    id           bigserial PRIMARY KEY,
+    todo_id      text NOT NULL,
+    action       text NOT NULL,
+    before_state jsonb,
+    after_state  jsonb,
+    actor        text NOT NULL,
+    request_id   text,
+    created_at   timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_todo_history_todo_id ON todo_history (todo_id, id);
@@ -0,0 +1,2 @@
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS chk_todos_status;
+ALTER TABLE todos ALTER COLUMN status DROP NOT NULL;
@@ -0,0 +1,8 @@
+UPDATE todos SET status = CASE
+    WHEN lower(trim(status)) IN ('done', 'complete', 'completed', 'finished') THEN 'done'
+    WHEN lower(trim(status)) IN ('in_progress', 'in progress', 'in-progress', 'doing', 'started') THEN 'in_progress'
+    WHEN lower(trim(status)) = 'reopened' THEN 'reopened'
+    ELSE 'pending'
+END;
+ALTER TABLE todos ALTER COLUMN status SET NOT NULL;
+ALTER TABLE todos ADD CONSTRAINT chk_todos_status CHECK (status IN ('pending', 'in_progress', 'done', 'reopened'));
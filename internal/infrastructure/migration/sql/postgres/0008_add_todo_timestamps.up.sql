@@ -0,0 +1,7 @@
+ALTER TABLE todos ADD COLUMN created_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE todos ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE todos ADD COLUMN completed_at timestamptz;
+UPDATE todos SET completed_at = updated_at WHERE status = 'done';
+CREATE INDEX idx_todos_created_at ON todos (created_at, id);
+CREATE INDEX idx_todos_updated_at ON todos (updated_at, id);
+CREATE INDEX idx_todos_completed_at ON todos (completed_at, id) WHERE completed_at IS NOT NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE todos ADD COLUMN due_at timestamptz;
+ALTER TABLE todos ADD COLUMN due_all_day boolean NOT NULL DEFAULT false;
+ALTER TABLE todos ADD COLUMN reminders text NOT NULL DEFAULT '[]';
+ALTER TABLE todos ADD COLUMN next_reminder_at timestamptz;
+CREATE INDEX idx_todos_due_at ON todos (due_at, id) WHERE due_at IS NOT NULL;
+CREATE INDEX idx_todos_next_reminder_at ON todos (next_reminder_at) WHERE next_reminder_at IS NOT NULL;
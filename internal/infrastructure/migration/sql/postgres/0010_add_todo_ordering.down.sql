@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_todos_rank;
+DROP INDEX IF EXISTS idx_todos_position;
+ALTER TABLE todos DROP COLUMN position;
+ALTER TABLE todos DROP COLUMN priority;
@@ -0,0 +1,7 @@
+ALTER TABLE todos ADD COLUMN priority smallint NOT NULL DEFAULT 0 CONSTRAINT chk_todos_priority CHECK (priority BETWEEN 0 AND 4);
+ALTER TABLE todos ADD COLUMN position text NOT NULL DEFAULT '';
+UPDATE todos SET position = ranked.position
+FROM (SELECT id, 'i' || lpad(row_number() OVER (ORDER BY id)::text, 10, '0') || 'i' AS position FROM todos) AS ranked
+WHERE todos.id = ranked.id;
+CREATE INDEX idx_todos_position ON todos (position);
+CREATE INDEX idx_todos_rank ON todos ((CAST(4 - priority AS TEXT) || position), id);
@@ -0,0 +1,2 @@
+ALTER TABLE todos ADD COLUMN parent_id text REFERENCES todos (id);
+CREATE INDEX idx_todos_parent_id ON todos (parent_id) WHERE parent_id IS NOT NULL;
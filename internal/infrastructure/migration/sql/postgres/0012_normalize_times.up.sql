@@ -0,0 +1,2 @@
+-- Postgres compares timestamptz as instants, so this version only keeps numbering aligned with sqlite.
+SELECT 1;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS todos (
+    id          text PRIMARY KEY,
+    topic       text,
+    description text,
+    status      text
+);
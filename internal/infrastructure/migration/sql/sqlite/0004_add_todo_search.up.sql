@@ -0,0 +1,2 @@
+-- SQLite searches with LIKE, so this version only keeps numbering aligned with postgres.
+SELECT 1;
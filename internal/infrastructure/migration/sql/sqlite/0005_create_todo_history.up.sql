@@ -0,0 +1,11 @@
+CREATE TABLE todo_history (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    todo_id      text NOT NULL,
+    action       text NOT NULL,
+    before_state text,
+    after_state  text,
+    actor        text NOT NULL,
+    request_id   text,
+    created_at   datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_todo_history_todo_id ON todo_history (todo_id, id);
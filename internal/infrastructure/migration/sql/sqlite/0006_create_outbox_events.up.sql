@@ -0,0 +1,10 @@
+CREATE TABLE outbox_events (
+    id           text PRIMARY KEY,
+    event_type   text NOT NULL,
+    aggregate_id text NOT NULL,
+    payload      text NOT NULL,
+    occurred_at  datetime NOT NULL,
+    published_at datetime,
+    attempts     integer NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events (occurred_at) WHERE published_at IS NULL;
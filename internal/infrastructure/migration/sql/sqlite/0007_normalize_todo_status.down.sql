@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trg_todos_status_update;
+DROP TRIGGER IF EXISTS trg_todos_status_insert;
@@ -0,0 +1,16 @@
+UPDATE todos SET status = CASE
+    WHEN lower(trim(status)) IN ('done', 'complete', 'completed', 'finished') THEN 'done'
+    WHEN lower(trim(status)) IN ('in_progress', 'in progress', 'in-progress', 'doing', 'started') THEN 'in_progress'
+    WHEN lower(trim(status)) = 'reopened' THEN 'reopened'
+    ELSE 'pending'
+END;
+CREATE TRIGGER trg_todos_status_insert BEFORE INSERT ON todos
+WHEN NEW.status IS NULL OR NEW.status NOT IN ('pending', 'in_progress', 'done', 'reopened')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid todo status');
+END;
+CREATE TRIGGER trg_todos_status_update BEFORE UPDATE OF status ON todos
+WHEN NEW.status IS NULL OR NEW.status NOT IN ('pending', 'in_progress', 'done', 'reopened')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid todo status');
+END;
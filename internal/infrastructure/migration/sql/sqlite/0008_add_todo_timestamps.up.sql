@@ -0,0 +1,8 @@
+ALTER TABLE todos ADD COLUMN created_at datetime;
+ALTER TABLE todos ADD COLUMN updated_at datetime;
+ALTER TABLE todos ADD COLUMN completed_at datetime;
+UPDATE todos SET created_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now');
+UPDATE todos SET completed_at = updated_at WHERE status = 'done';
+CREATE INDEX idx_todos_created_at ON todos (created_at, id);
+CREATE INDEX idx_todos_updated_at ON todos (updated_at, id);
+CREATE INDEX idx_todos_completed_at ON todos (completed_at, id);
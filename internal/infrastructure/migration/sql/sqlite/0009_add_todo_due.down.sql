@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_todos_next_reminder_at;
+DROP INDEX IF EXISTS idx_todos_due_at;
+ALTER TABLE todos DROP COLUMN next_reminder_at;
+ALTER TABLE todos DROP COLUMN reminders;
+ALTER TABLE todos DROP COLUMN due_all_day;
+ALTER TABLE todos DROP COLUMN due_at;
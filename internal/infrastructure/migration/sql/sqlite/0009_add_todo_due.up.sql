@@ -0,0 +1,6 @@
+ALTER TABLE todos ADD COLUMN due_at datetime;
+ALTER TABLE todos ADD COLUMN due_all_day boolean NOT NULL DEFAULT false;
+ALTER TABLE todos ADD COLUMN reminders text NOT NULL DEFAULT '[]';
+ALTER TABLE todos ADD COLUMN next_reminder_at datetime;
+CREATE INDEX idx_todos_due_at ON todos (due_at, id);
+CREATE INDEX idx_todos_next_reminder_at ON todos (next_reminder_at);
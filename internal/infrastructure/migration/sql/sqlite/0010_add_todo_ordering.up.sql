@@ -0,0 +1,9 @@
+ALTER TABLE todos ADD COLUMN priority integer NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 4);
+ALTER TABLE todos ADD COLUMN position text NOT NULL DEFAULT '';
+UPDATE todos SET position = (
+	SELECT printf('i%010di', ranked.n)
+	FROM (SELECT id, row_number() OVER (ORDER BY id) AS n FROM todos) AS ranked
+	WHERE ranked.id = todos.id
+);
+CREATE INDEX idx_todos_position ON todos (position);
+CREATE INDEX idx_todos_rank ON todos ((CAST(4 - priority AS TEXT) || position), id);
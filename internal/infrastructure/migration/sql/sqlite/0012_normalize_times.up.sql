@@ -0,0 +1,12 @@
+-- Times are compared as text, so every stored one is rewritten in UTC like the adapter writes them.
+UPDATE todos SET
+    created_at = strftime('%Y-%m-%d %H:%M:%f+00:00', created_at),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', updated_at),
+    completed_at = strftime('%Y-%m-%d %H:%M:%f+00:00', completed_at),
+    deleted_at = strftime('%Y-%m-%d %H:%M:%f+00:00', deleted_at),
+    due_at = strftime('%Y-%m-%d %H:%M:%f+00:00', due_at),
+    next_reminder_at = strftime('%Y-%m-%d %H:%M:%f+00:00', next_reminder_at);
+UPDATE todo_history SET created_at = strftime('%Y-%m-%d %H:%M:%f+00:00', created_at);
+UPDATE outbox_events SET
+    occurred_at = strftime('%Y-%m-%d %H:%M:%f+00:00', occurred_at),
+    published_at = strftime('%Y-%m-%d %H:%M:%f+00:00', published_at);
@@ -2,20 +2,127 @@ package infrastructure
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"log"
+	"os"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 )
 
-var RedisClient *redis.Client
+const (
+	RedisStandalone = "standalone"
+	RedisSentinel   = "sentinel"
+	RedisCluster    = "cluster"
+)
+
+// RedisClient is a single node, Sentinel-managed or Cluster client depending on redis.mode.
+var RedisClient redis.UniversalClient
 
 func InitRedis() {
-	host := viper.GetString("redis.host")
-	port := viper.GetString("redis.port")
-	RedisClient = redis.NewClient(&redis.Options{Addr: host + ":" + port})
-	_, err := RedisClient.Ping(context.Background()).Result()
+	options, err := redisOptions()
+	if err != nil {
+		log.Fatal("Invalid Redis configuration : ", err)
+	}
+
+	switch viper.GetString("redis.mode") {
+	case RedisSentinel:
+		RedisClient = redis.NewFailoverClient(options.Failover())
+	case RedisCluster:
+		RedisClient = redis.NewClusterClient(options.Cluster())
+	default:
+		RedisClient = redis.NewClient(options.Simple())
+	}
+
+	// The cache's circuit breaker bypasses Redis until it answers, so an outage is not fatal at startup.
+	_, err = RedisClient.Ping(context.Background()).Result()
 	if err != nil {
-		log.Fatal("Could not connect to Redis: ", err)
+		log.Print("Could not connect to Redis, starting with the cache bypassed: ", err)
+	}
+}
+
+// redisOptions reads the redis section. Zero pool sizes and timeouts keep the go-redis defaults.
+func redisOptions() (*redis.UniversalOptions, error) {
+	mode := viper.GetString("redis.mode")
+	addrs := viper.GetStringSlice("redis.addrs")
+	if len(addrs) == 0 {
+		addrs = []string{viper.GetString("redis.host") + ":" + viper.GetString("redis.port")}
+	}
+
+	options := &redis.UniversalOptions{
+		Addrs:            addrs,
+		DB:               viper.GetInt("redis.db"),
+		Username:         viper.GetString("redis.username"),
+		Password:         viper.GetString("redis.password"),
+		SentinelUsername: viper.GetString("redis.sentinel.username"),
+		SentinelPassword: viper.GetString("redis.sentinel.password"),
+		MasterName:       viper.GetString("redis.sentinel.master_name"),
+		PoolSize:         viper.GetInt("redis.pool.size"),
+		MinIdleConns:     viper.GetInt("redis.pool.min_idle"),
+		PoolTimeout:      viper.GetDuration("redis.pool.timeout"),
+		DialTimeout:      viper.GetDuration("redis.timeout.dial"),
+		ReadTimeout:      viper.GetDuration("redis.timeout.read"),
+		WriteTimeout:     viper.GetDuration("redis.timeout.write"),
+	}
+
+	switch mode {
+	case "", RedisStandalone:
+	case RedisSentinel:
+		if options.MasterName == "" {
+			return nil, errors.New("redis.sentinel.master_name is required in sentinel mode")
+		}
+	case RedisCluster:
+		if options.DB != 0 {
+			return nil, errors.New("redis.db must be 0 in cluster mode")
+		}
+	default:
+		return nil, fmt.Errorf("unknown redis.mode %q", mode)
+	}
+
+	if viper.GetBool("redis.tls.enabled") {
+		tlsConfig, err := redisTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		options.TLSConfig = tlsConfig
+	}
+	return options, nil
+}
+
+// redisTLSConfig trusts redis.tls.ca_file on top of the system roots and presents a client
+// certificate when redis.tls.cert_file and key_file are set.
+func redisTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         viper.GetString("redis.tls.server_name"),
+		InsecureSkipVerify: viper.GetBool("redis.tls.insecure_skip_verify"),
+	}
+
+	if caFile := viper.GetString("redis.tls.ca_file"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	certFile, keyFile := viper.GetString("redis.tls.cert_file"), viper.GetString("redis.tls.key_file")
+	if certFile != "" || keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
 	}
+	return tlsConfig, nil
 }
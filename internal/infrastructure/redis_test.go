@@ -0,0 +1,191 @@
+package infrastructure
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisOptions(t *testing.T) {
+	testCases := []struct {
+		description string
+		config      map[string]any
+		assert      func(t *testing.T, options *redis.UniversalOptions)
+		expectedErr string
+	}{
+		{
+			description: "standalone dials host and port when addrs is empty",
+			config:      map[string]any{"redis.mode": "standalone", "redis.host": "cache", "redis.port": "6380", "redis.db": 2, "redis.password": "secret"},
+			assert: func(t *testing.T, options *redis.UniversalOptions) {
+				simple := options.Simple()
+				assert.Equal(t, "cache:6380", simple.Addr)
+				assert.Equal(t, 2, simple.DB)
+				assert.Equal(t, "secret", simple.Password)
+			},
+		},
+		{
+			description: "an empty mode is standalone",
+			config:      map[string]any{"redis.addrs": []string{"cache:6379"}},
+			assert: func(t *testing.T, options *redis.UniversalOptions) {
+				assert.Equal(t, "cache:6379", options.Simple().Addr)
+			},
+		},
+		{
+			description: "sentinel asks the sentinels in addrs for the master",
+			config: map[string]any{
+				"redis.mode": "sentinel", "redis.addrs": []string{"sentinel-1:26379", "sentinel-2:26379"},
+				"redis.sentinel.master_name": "todo", "redis.sentinel.password": "sentinel-secret", "redis.password": "secret",
+			},
+			assert: func(t *testing.T, options *redis.UniversalOptions) {
+				failover := options.Failover()
+				assert.Equal(t, "todo", failover.MasterName)
+				assert.Equal(t, []string{"sentinel-1:26379", "sentinel-2:26379"}, failover.SentinelAddrs)
+				assert.Equal(t, "sentinel-secret", failover.SentinelPassword)
+				assert.Equal(t, "secret", failover.Password)
+			},
+		},
+		{
+			description: "sentinel needs a master name",
+			config:      map[string]any{"redis.mode": "sentinel", "redis.addrs": []string{"sentinel-1:26379"}},
+			expectedErr: "redis.sentinel.master_name is required in sentinel mode",
+		},
+		{
+			description: "cluster seeds from addrs",
+			config:      map[string]any{"redis.mode": "cluster", "redis.addrs": []string{"node-1:6379", "node-2:6379"}, "redis.pool.size": 20, "redis.timeout.read": "2s"},
+			assert: func(t *testing.T, options *redis.UniversalOptions) {
+				cluster := options.Cluster()
+				assert.Equal(t, []string{"node-1:6379", "node-2:6379"}, cluster.Addrs)
+				assert.Equal(t, 20, cluster.PoolSize)
+				assert.Equal(t, 2*time.Second, cluster.ReadTimeout)
+			},
+		},
+		{
+			description: "cluster only has db 0",
+			config:      map[string]any{"redis.mode": "cluster", "redis.addrs": []string{"node-1:6379"}, "redis.db": 1},
+			expectedErr: "redis.db must be 0 in cluster mode",
+		},
+		{
+			description: "an unknown mode is rejected",
+			config:      map[string]any{"redis.mode": "replicated"},
+			expectedErr: `unknown redis.mode "replicated"`,
+		},
+		{
+			description: "tls is off unless enabled",
+			config:      map[string]any{"redis.mode": "standalone"},
+			assert: func(t *testing.T, options *redis.UniversalOptions) {
+				assert.Nil(t, options.TLSConfig)
+			},
+		},
+		{
+			description: "tls is passed on to every mode",
+			config:      map[string]any{"redis.mode": "cluster", "redis.addrs": []string{"node-1:6379"}, "redis.tls.enabled": true, "redis.tls.server_name": "redis.internal"},
+			assert: func(t *testing.T, options *redis.UniversalOptions) {
+				tlsConfig := options.Cluster().TLSConfig
+				assert.Equal(t, "redis.internal", tlsConfig.ServerName)
+				assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			setConfig(t, testCase.config)
+
+			options, err := redisOptions()
+
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			testCase.assert(t, options)
+		})
+	}
+}
+
+func TestRedisTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+	emptyFile := filepath.Join(dir, "empty.pem")
+	assert.NoError(t, os.WriteFile(emptyFile, []byte("not a certificate"), 0o600))
+
+	testCases := []struct {
+		description          string
+		config               map[string]any
+		expectedCertificates int
+		expectedErr          bool
+	}{
+		{description: "a ca file is trusted", config: map[string]any{"redis.tls.ca_file": certFile}},
+		{description: "a client certificate is presented", config: map[string]any{"redis.tls.cert_file": certFile, "redis.tls.key_file": keyFile}, expectedCertificates: 1},
+		{description: "a missing ca file is an error", config: map[string]any{"redis.tls.ca_file": filepath.Join(dir, "missing.pem")}, expectedErr: true},
+		{description: "a ca file without certificates is an error", config: map[string]any{"redis.tls.ca_file": emptyFile}, expectedErr: true},
+		{description: "a certificate without its key is an error", config: map[string]any{"redis.tls.cert_file": certFile}, expectedErr: true},
+		{description: "a key that does not match the certificate is an error", config: map[string]any{"redis.tls.cert_file": certFile, "redis.tls.key_file": emptyFile}, expectedErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			config := map[string]any{"redis.mode": "standalone", "redis.tls.enabled": true}
+			for key, value := range testCase.config {
+				config[key] = value
+			}
+			setConfig(t, config)
+
+			options, err := redisOptions()
+
+			if testCase.expectedErr {
+				assert.Error(t, err)
+				assert.Nil(t, options)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, options.TLSConfig.Certificates, testCase.expectedCertificates)
+			if _, ok := testCase.config["redis.tls.ca_file"]; ok {
+				assert.NotNil(t, options.TLSConfig.RootCAs)
+			}
+		})
+	}
+}
+
+// setConfig replaces the viper configuration with values for the length of the test.
+func setConfig(t *testing.T, values map[string]any) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	for key, value := range values {
+		viper.Set(key, value)
+	}
+}
+
+// writeCertificate writes a self-signed certificate and its key to dir.
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "redis.internal"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certFile, keyFile
+}
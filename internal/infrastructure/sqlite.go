@@ -0,0 +1,21 @@
+package infrastructure
+
+import (
+	"log"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/sqlite"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+func InitSQLite() {
+	path := viper.GetString("database.sqlite.path")
+
+	db, err := gorm.Open(sqlite.Open(path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"), &gorm.Config{})
+
+	if err != nil {
+		log.Fatal("Could not open SQLite database : ", err)
+	}
+
+	Db = db
+}
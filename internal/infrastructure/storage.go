@@ -0,0 +1,29 @@
+package infrastructure
+
+import "github.com/spf13/viper"
+
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+	StorageRedis    = "redis"
+)
+
+// InitDatabase opens the database selected by storage.repository. The memory backend needs none.
+func InitDatabase() {
+	switch viper.GetString("storage.repository") {
+	case StorageMemory:
+	case StorageSQLite:
+		InitSQLite()
+	default:
+		InitPostgres()
+	}
+}
+
+// InitCache connects to Redis unless storage.cache selects the in-process cache.
+func InitCache() {
+	// The event stream publisher shares the Redis client even when todos are cached in memory.
+	if viper.GetString("storage.cache") != StorageMemory || viper.GetString("events.publisher") == StorageRedis {
+		InitRedis()
+	}
+}
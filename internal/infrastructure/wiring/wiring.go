@@ -0,0 +1,195 @@
+// Package wiring builds the adapters selected in the configuration, shared by every command.
+package wiring
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/memory"
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/notifier"
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/postgres"
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/redis"
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/serialization"
+	"github.com/VanillaSkys/todo_fiber/internal/adapter/out/sqlite"
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/cache"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/codec"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/event"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/notify"
+	"github.com/VanillaSkys/todo_fiber/internal/core/port/repository"
+	"github.com/VanillaSkys/todo_fiber/internal/infrastructure"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"github.com/spf13/viper"
+)
+
+// Repositories are the todo repositories of one storage backend, sharing its transactor.
+type Repositories struct {
+	Todo       repository.TodoRepository
+	History    repository.TodoHistoryRepository
+	Outbox     repository.OutboxRepository
+	Transactor repository.Transactor
+}
+
+// TodoRepositories builds the repositories selected by storage.repository.
+func TodoRepositories() Repositories {
+	switch viper.GetString("storage.repository") {
+	case infrastructure.StorageMemory:
+		return Repositories{
+			Todo:       memory.NewMemoryTodoRepository(),
+			History:    memory.NewMemoryTodoHistoryRepository(),
+			Outbox:     memory.NewMemoryOutboxRepository(),
+			Transactor: memory.NewMemoryTransactor(),
+		}
+	case infrastructure.StorageSQLite:
+		return Repositories{
+			Todo:       sqlite.NewSQLiteTodoRepository(infrastructure.Db),
+			History:    postgres.NewGormTodoHistoryRepository(infrastructure.Db),
+			Outbox:     postgres.NewGormOutboxRepository(infrastructure.Db),
+			Transactor: postgres.NewGormTransactor(infrastructure.Db),
+		}
+	default:
+		return Repositories{
+			Todo:       postgres.NewGormTodoRepository(infrastructure.Db),
+			History:    postgres.NewGormTodoHistoryRepository(infrastructure.Db),
+			Outbox:     postgres.NewGormOutboxRepository(infrastructure.Db),
+			Transactor: postgres.NewGormTransactor(infrastructure.Db),
+		}
+	}
+}
+
+// EventPublisher builds the publisher selected by events.publisher, logging events by default.
+func EventPublisher() event.Publisher {
+	if viper.GetString("events.publisher") == infrastructure.StorageRedis {
+		return redis.NewRedisStreamPublisher(
+			infrastructure.RedisClient,
+			viper.GetString("events.stream"),
+			viper.GetInt64("events.max_len"),
+		)
+	}
+	return memory.NewLogPublisher()
+}
+
+// ReminderNotifier builds the notifier selected by reminders.notifier: todo events on the event
+// publisher, or the log by default.
+func ReminderNotifier() notify.Notifier {
+	if viper.GetString("reminders.notifier") == "events" {
+		return notifier.NewEventNotifier(EventPublisher())
+	}
+	return notifier.NewLogNotifier()
+}
+
+// TodoCache builds the configured cache. A Redis cache sits behind a circuit breaker, returned
+// as the health signal; the in-memory cache cannot fail over so it has none.
+func TodoCache() (cache.Cache, cache.Health) {
+	namespace := viper.GetString("cache.namespace")
+	if viper.GetString("storage.cache") == infrastructure.StorageMemory {
+		return cache.NewNamespacedCache(memory.NewMemoryCache(), namespace), nil
+	}
+
+	todoCache := redis.NewRedisCache(infrastructure.RedisClient)
+	if viper.GetBool("cache.l1.enabled") {
+		todoCache = redis.NewTieredCache(
+			context.Background(),
+			memory.NewLRUCache(viper.GetInt("cache.l1.size"), viper.GetDuration("cache.l1.ttl")),
+			todoCache,
+			infrastructure.RedisClient,
+			viper.GetString("cache.l1.channel"),
+		)
+	}
+	breaker := cache.NewCircuitBreaker(
+		todoCache,
+		viper.GetInt("cache.breaker.threshold"),
+		viper.GetDuration("cache.breaker.cooldown"),
+		func(bypassed bool) {
+			if bypassed {
+				logger.Log.Warn("Cache failing, bypassing it until it recovers.")
+				return
+			}
+			logger.Log.Info("Cache recovered.")
+		},
+	)
+	return cache.NewNamespacedCache(breaker, namespace), breaker
+}
+
+// TodoCodec builds the codec selected by cache.codec, compressed per cache.compression. An unknown
+// codec or compression is a configuration error and stops the process.
+func TodoCodec() codec.Codec {
+	todoCodec, err := serialization.NewCodec(
+		viper.GetString("cache.codec"),
+		viper.GetString("cache.compression"),
+		viper.GetInt("cache.compression_threshold"),
+	)
+	if err != nil {
+		log.Fatal("Invalid cache codec : ", err)
+	}
+	return todoCodec
+}
+
+// TodoCacheWrite reads the cache.write strategy. Write-behind buffers writes in a Redis stream,
+// or in process when the cache is in memory.
+func TodoCacheWrite() service.CacheWrite {
+	strategy := viper.GetString("cache.write.strategy")
+	switch strategy {
+	case "", service.CacheWriteThrough, service.CacheWriteInvalidate:
+		return service.CacheWrite{Strategy: strategy}
+	case service.CacheWriteBehind:
+	default:
+		log.Fatal("Invalid cache write strategy : ", strategy)
+	}
+
+	claimTimeout := viper.GetDuration("cache.write.behind.claim_timeout")
+	if viper.GetString("storage.cache") == infrastructure.StorageMemory {
+		return service.CacheWrite{Strategy: strategy, Buffer: memory.NewMemoryWriteBuffer(claimTimeout)}
+	}
+
+	minReplicas := viper.GetInt("cache.write.behind.min_replicas")
+	if minReplicas > 0 && viper.GetString("redis.mode") == infrastructure.RedisCluster {
+		log.Fatal("cache.write.behind.min_replicas is not supported in redis cluster mode")
+	}
+	consumer, err := os.Hostname()
+	if err != nil {
+		log.Fatal("Could not name the write-behind consumer : ", err)
+	}
+	return service.CacheWrite{
+		Strategy: strategy,
+		Buffer: redis.NewRedisWriteBuffer(
+			infrastructure.RedisClient,
+			viper.GetString("cache.write.behind.stream"),
+			viper.GetString("cache.write.behind.group"),
+			consumer,
+			claimTimeout,
+			minReplicas,
+			viper.GetDuration("cache.write.behind.replica_timeout"),
+		),
+	}
+}
+
+// TodoCacheSchema reads cache.schema_version. Empty leaves the service to derive it from the todo struct.
+func TodoCacheSchema() string {
+	return viper.GetString("cache.schema_version")
+}
+
+func TodoCacheTTL() service.CacheTTL {
+	return service.CacheTTL{
+		List:      viper.GetDuration("cache.ttl.list"),
+		ListFresh: viper.GetDuration("cache.ttl.list_fresh"),
+		Item:      viper.GetDuration("cache.ttl.item"),
+	}
+}
+
+// TodoSubtaskPolicy reads todo.subtasks, what completing or deleting a todo does to its open subtasks.
+func TodoSubtaskPolicy() service.SubtaskPolicy {
+	policy := service.SubtaskPolicy{
+		Complete: viper.GetString("todo.subtasks.on_complete"),
+		Delete:   viper.GetString("todo.subtasks.on_delete"),
+	}
+	for _, value := range []string{policy.Complete, policy.Delete} {
+		switch value {
+		case "", service.SubtaskReject, service.SubtaskCascade:
+		default:
+			log.Fatal("Invalid subtask policy : ", value)
+		}
+	}
+	return policy
+}
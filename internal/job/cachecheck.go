@@ -0,0 +1,42 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+// RunCacheCheck compares the cache with the repository every interval until ctx is done,
+// repairing drift when repair is set.
+func RunCacheCheck(ctx context.Context, checker service.CacheChecker, interval time.Duration, repair bool) {
+	if interval <= 0 {
+		logger.Log.Info("Scheduled cache consistency check disabled.")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := checker.Check(ctx, repair)
+			if err != nil {
+				logger.Log.Error("Error checking cache consistency", zap.Error(err))
+				continue
+			}
+			if !report.Consistent() {
+				logger.Log.Warn("Cache differs from the repository.",
+					zap.Strings("stale", report.Stale),
+					zap.Strings("extra", report.Extra),
+					zap.Bool("repaired", report.Repaired),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package job
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+// leaseHeartbeats is how many heartbeats a namespace stays live without one, so a slow beat is not
+// mistaken for a stopped instance.
+const leaseHeartbeats = 3
+
+// RunCacheNamespaces marks this instance's cache namespace live every heartbeat and collects the
+// keys of namespaces no instance marks every gcInterval, until ctx is done.
+func RunCacheNamespaces(ctx context.Context, namespaces service.CacheNamespaces, heartbeat time.Duration, gcInterval time.Duration) {
+	if heartbeat <= 0 {
+		logger.Log.Error("Cache namespace heartbeat disabled, other instances may collect this namespace. Heartbeat must be positive.")
+		return
+	}
+	beat := func() {
+		if err := namespaces.Heartbeat(ctx, leaseHeartbeats*heartbeat); err != nil {
+			logger.Log.Error("Error marking cache namespace live", zap.Error(err))
+		}
+	}
+	beat()
+
+	heartbeatTicker := time.NewTicker(heartbeat)
+	defer heartbeatTicker.Stop()
+
+	var gc <-chan time.Time
+	if gcInterval > 0 {
+		gcTicker := time.NewTicker(gcInterval)
+		defer gcTicker.Stop()
+		gc = gcTicker.C
+	} else {
+		logger.Log.Info("Cache namespace garbage collection disabled.")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeatTicker.C:
+			beat()
+		case <-gc:
+			deleted, err := namespaces.CollectGarbage(ctx)
+			if err != nil {
+				logger.Log.Error("Error collecting old cache namespaces", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				logger.Log.Info("Collected old cache namespaces.", zap.Int("keys", deleted))
+			}
+		}
+	}
+}
+
+// RunCacheWarmUp preloads pages of the todo cache within timeout, then sets ready. A failed warm-up
+// is logged and still sets ready, since the cache fills on demand.
+func RunCacheWarmUp(ctx context.Context, warmer service.CacheWarmer, pages int, timeout time.Duration, ready *atomic.Bool) {
+	defer ready.Store(true)
+	if pages <= 0 {
+		return
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	warmed, err := warmer.WarmUp(ctx, pages)
+	if err != nil {
+		logger.Log.Warn("Cache warm-up stopped early", zap.Int("todos", warmed), zap.Error(err))
+		return
+	}
+	logger.Log.Info("Cache warmed up.", zap.Int("todos", warmed))
+}
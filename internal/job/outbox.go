@@ -0,0 +1,38 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+// RunOutboxRelay publishes pending todo events every interval until ctx is done.
+func RunOutboxRelay(ctx context.Context, relay service.OutboxRelay, interval time.Duration, batchSize int) {
+	if interval <= 0 || batchSize <= 0 {
+		logger.Log.Warn("Outbox relay disabled, relay interval and batch size must be positive.")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				published, err := relay.Relay(ctx, batchSize)
+				if err != nil {
+					logger.Log.Error("Error relaying todo events", zap.Error(err))
+				}
+				if err != nil || published < batchSize {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+// RunTrashPurge permanently removes todos that have been in the trash longer than retention,
+// checking every interval until ctx is done.
+func RunTrashPurge(ctx context.Context, todoService service.TodoService, retention time.Duration, interval time.Duration) {
+	if retention <= 0 || interval <= 0 {
+		logger.Log.Warn("Trash purge disabled, retention and purge interval must be positive.")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := todoService.PurgeDeletedBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				logger.Log.Error("Error purging trashed todos", zap.Error(err))
+				continue
+			}
+			if purged > 0 {
+				logger.Log.Info("Purged trashed todos.", zap.Int("count", purged))
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+// RunReminderDispatch sends todo reminders that have come due every interval until ctx is done.
+func RunReminderDispatch(ctx context.Context, dispatcher service.ReminderDispatcher, interval time.Duration, batchSize int) {
+	if interval <= 0 || batchSize <= 0 {
+		logger.Log.Warn("Reminder dispatcher disabled, reminder interval and batch size must be positive.")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				// A full batch may leave more due, whether or not each of its todos had a reminder to send.
+				taken, err := dispatcher.Dispatch(ctx, batchSize)
+				if err != nil {
+					logger.Log.Error("Error dispatching todo reminders", zap.Error(err))
+				}
+				if err != nil || taken < batchSize {
+					break
+				}
+			}
+		}
+	}
+}
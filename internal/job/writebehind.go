@@ -0,0 +1,38 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/service"
+	"github.com/VanillaSkys/todo_fiber/internal/logger"
+	"go.uber.org/zap"
+)
+
+// RunWriteBehindFlush stores buffered todo writes in the repository every interval until ctx is done.
+func RunWriteBehindFlush(ctx context.Context, flusher service.WriteBehindFlusher, interval time.Duration, batchSize int) {
+	if interval <= 0 || batchSize <= 0 {
+		logger.Log.Error("Write-behind flush disabled, buffered writes will not reach the repository. Flush interval and batch size must be positive.")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				flushed, err := flusher.Flush(ctx, batchSize)
+				if err != nil {
+					logger.Log.Error("Error flushing buffered todo writes", zap.Error(err))
+				}
+				if err != nil || flushed < batchSize {
+					break
+				}
+			}
+		}
+	}
+}
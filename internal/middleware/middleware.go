@@ -1,6 +1,10 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/VanillaSkys/todo_fiber/internal/core/domain/requestctx"
 	"github.com/VanillaSkys/todo_fiber/internal/logger"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
@@ -17,6 +21,7 @@ func SetRequestId() fiber.Handler {
 
 		loggerWithReqID := logger.AddRequestIDToLogger(reqId)
 		c.Locals("X-Request-ID", reqId)
+		c.SetUserContext(requestctx.WithRequestId(c.UserContext(), reqId))
 		loggerWithReqID.Info("Incoming request", zap.String("method", c.Method()), zap.String("path", c.Path()))
 
 		c.Locals("logger", loggerWithReqID)
@@ -28,3 +33,25 @@ func SetRequestId() fiber.Handler {
 		return err
 	}
 }
+
+// SetActor records the caller named by the X-Actor header for audit entries. The header is the
+// client's own claim and nothing authenticates it, so the actor is stored as unverified.
+func SetActor() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if actor := c.Get("X-Actor", ""); actor != "" {
+			c.SetUserContext(requestctx.WithActor(c.UserContext(), requestctx.UnverifiedActor(actor)))
+		}
+		return c.Next()
+	}
+}
+
+// RequireToken rejects requests whose Authorization header does not carry token as a bearer token.
+func RequireToken(token string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		given, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization, ""), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing or invalid bearer token."})
+		}
+		return c.Next()
+	}
+}